@@ -36,6 +36,20 @@
 //
 //	edgeql-go -help
 //
+// # Custom Scalars
+//
+// By default scalar types are generated as the go type of their base type.
+// Use the -scalar option to generate a different go type for a scalar type
+// and register the type's codec with Client.RegisterScalarCodec() at runtime.
+//
+//	//go:generate edgeql-go -scalar default::Money=github.com/acme/money.Money
+//
+// The go type's package name is the last element of its import path, not
+// counting a major version suffix like /v2.
+//
+// The same go type is used for optional values so it should implement
+// marshal.OptionalUnmarshaler if the scalar is used in optional shape fields.
+//
 // [pinning tool dependencies]: https://github.com/golang/go/wiki/Modules#how-can-i-track-tool-dependencies-for-a-module
 // [go generate]: https://go.dev/blog/generate
 package main
@@ -28,6 +28,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/edgedb/edgedb-go/internal"
 	edgedb "github.com/edgedb/edgedb-go/internal/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,9 @@ var tests = []struct {
 	description string
 	directory   string
 	args        []string
+
+	// minProtocol is the oldest protocol version the test can run with.
+	minProtocol internal.ProtocolVersion
 }{
 	{
 		description: "invoke edgeql-go without args",
@@ -60,6 +64,20 @@ var tests = []struct {
 		directory:   "testdata/pubtypes",
 		args:        []string{"-pubtypes"},
 	},
+	{
+		description: "invoke edgeql-go with -scalar",
+		directory:   "testdata/scalar",
+		args:        []string{"-scalar", "std::decimal=test/money/v2.Money"},
+		// Custom scalars need scalar type names in type descriptors.
+		minProtocol: internal.ProtocolVersion{Major: 2, Minor: 0},
+	},
+	{
+		description: "invoke edgeql-go with ext::pgvector::vector types",
+		directory:   "testdata/pgvector",
+		args:        []string{},
+		// The test server only has the pgvector extension on EdgeDB 5.0+.
+		minProtocol: internal.ProtocolVersion{Major: 2, Minor: 0},
+	},
 }
 
 func TestMain(m *testing.M) {
@@ -81,7 +99,15 @@ func TestMain(m *testing.M) {
 }
 
 func TestEdgeQLGo(t *testing.T) {
+	protocolVersion := edgedb.TestClientProtocolVersion()
 	for _, test := range tests {
+		if protocolVersion.LT(test.minProtocol) {
+			t.Run(test.description, func(t *testing.T) {
+				t.Skip("server version is too old for this test")
+			})
+			continue
+		}
+
 		t.Run(test.description, runTest(test.directory, test.args))
 	}
 }
@@ -98,6 +124,11 @@ func runTest(dir string, args []string) func(*testing.T) {
 		edgeqlGo := filepath.Join(tmpDir, "edgeql-go")
 		run(t, ".", "go", "build", "-o", edgeqlGo)
 
+		// The generated code is built with this version of edgedb-go
+		// so that it can use types that haven't been released yet.
+		moduleDir, err := filepath.Abs("../..")
+		require.NoError(t, err)
+
 		var wg sync.WaitGroup
 		err = filepath.WalkDir(
 			dir,
@@ -134,6 +165,9 @@ func runTest(dir string, args []string) func(*testing.T) {
 			t.Run(entry.Name(), func(t *testing.T) {
 				projectDir := filepath.Join(tmpDir, entry.Name())
 				run(t, projectDir, edgeqlGo, args...)
+				run(t, projectDir, "go", "mod", "edit", "-replace",
+					"github.com/edgedb/edgedb-go="+moduleDir)
+				run(t, projectDir, "go", "mod", "tidy")
 				run(t, projectDir, "go", "run", "./...")
 				er := filepath.WalkDir(
 					projectDir,
@@ -81,7 +81,7 @@ func generateTypeV2(
 	case descriptor.Tuple:
 		types, imports, err = generateTupleV2(desc, required, path, cmdCfg)
 	case descriptor.BaseScalar, descriptor.Scalar, descriptor.Enum:
-		types, imports, err = generateBaseScalarV2(desc, required)
+		types, imports, err = generateBaseScalarV2(desc, required, cmdCfg)
 	case descriptor.Range:
 		types, imports, err = generateRangeV2(desc, required)
 	default:
@@ -491,6 +491,12 @@ func generateBaseScalar(
 		} else {
 			name = "edgedb.OptionalBigInt"
 		}
+	case codecs.DecimalID:
+		if required {
+			name = "edgedb.Decimal"
+		} else {
+			name = "edgedb.OptionalDecimal"
+		}
 	case codecs.RelativeDurationID:
 		if required {
 			name = "edgedb.RelativeDuration"
@@ -517,7 +523,17 @@ func generateBaseScalar(
 func generateBaseScalarV2(
 	desc *descriptor.V2,
 	required bool,
+	cmdCfg *cmdConfig,
 ) ([]goType, []string, error) {
+	if scalar, ok := lookupCustomScalar(desc, cmdCfg); ok {
+		var imports []string
+		if scalar.importPath != "" {
+			imports = append(imports, scalar.importPath)
+		}
+
+		return []goType{&goScalar{Name: scalar.name}}, imports, nil
+	}
+
 	if desc.Type == descriptor.Scalar {
 		desc = codecs.GetScalarDescriptorV2(desc)
 	}
@@ -533,6 +549,16 @@ func generateBaseScalarV2(
 		return []goType{&goScalar{Name: name}}, nil, nil
 	}
 
+	if desc.Name == codecs.VectorName {
+		if required {
+			name = "[]float32"
+		} else {
+			name = "edgedb.OptionalVector"
+		}
+
+		return []goType{&goScalar{Name: name}}, nil, nil
+	}
+
 	var imports []string
 	switch desc.ID {
 	case codecs.UUIDID:
@@ -627,6 +653,12 @@ func generateBaseScalarV2(
 		} else {
 			name = "edgedb.OptionalBigInt"
 		}
+	case codecs.DecimalID:
+		if required {
+			name = "edgedb.Decimal"
+		} else {
+			name = "edgedb.OptionalDecimal"
+		}
 	case codecs.RelativeDurationID:
 		if required {
 			name = "edgedb.RelativeDuration"
@@ -650,6 +682,25 @@ func generateBaseScalarV2(
 	return []goType{&goScalar{Name: name}}, imports, nil
 }
 
+// lookupCustomScalar finds the go type mapped to desc's type name or the
+// name of the closest ancestor that has a mapped go type.
+func lookupCustomScalar(
+	desc *descriptor.V2,
+	cmdCfg *cmdConfig,
+) (customScalar, bool) {
+	if scalar, ok := cmdCfg.scalars[desc.Name]; ok {
+		return scalar, true
+	}
+
+	for _, ancestor := range desc.Ancestors {
+		if scalar, ok := cmdCfg.scalars[ancestor.Desc.Name]; ok {
+			return scalar, true
+		}
+	}
+
+	return customScalar{}, false
+}
+
 func nameFromPath(path []string) string {
 	if len(path) == 0 {
 		return ""
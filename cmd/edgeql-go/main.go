@@ -30,6 +30,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -62,6 +63,87 @@ type cmdConfig struct {
 	mixedCaps bool
 	pubfuncs  bool
 	pubtypes  bool
+
+	// scalars maps scalar type names to user defined go types.
+	scalars scalarFlag
+}
+
+// customScalar is a user defined go type for a scalar type.
+type customScalar struct {
+	// name is the type's name as used in generated code, e.g. money.Money
+	name string
+
+	// importPath is the package that defines the type.
+	// It is empty for predeclared types.
+	importPath string
+}
+
+// scalarFlag is a repeatable flag that maps scalar type names to go types,
+// for example -scalar default::Money=github.com/acme/money.Money
+type scalarFlag map[string]customScalar
+
+func (f scalarFlag) String() string {
+	mappings := make([]string, 0, len(f))
+	for name, scalar := range f {
+		typ := scalar.name
+		if scalar.importPath != "" {
+			typ = scalar.importPath + typ[strings.Index(typ, "."):]
+		}
+		mappings = append(mappings, name+"="+typ)
+	}
+
+	sort.Strings(mappings)
+	return strings.Join(mappings, ",")
+}
+
+func (f scalarFlag) Set(value string) error {
+	name, typ, ok := strings.Cut(value, "=")
+	if !ok || name == "" || typ == "" {
+		return fmt.Errorf(
+			"expected scalar to be <scalar name>=<go type> got %q", value)
+	}
+
+	var scalar customScalar
+	if i := strings.LastIndex(typ, "."); i >= 0 {
+		scalar.importPath = typ[:i]
+		scalar.name = packageName(scalar.importPath) + typ[i:]
+	} else {
+		scalar.name = typ
+	}
+
+	f[name] = scalar
+	return nil
+}
+
+// packageName returns the default package name for importPath. Major
+// version suffixes like /v2 or .v2 are not part of the package name.
+func packageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+
+	if i := strings.LastIndex(name, "."); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// isMajorVersion returns true if s is a major version like v2.
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
 }
 
 func main() {
@@ -76,12 +158,18 @@ func main() {
 		"Make generated functions public.")
 	pubtypes := flag.Bool("pubtypes", false,
 		"Make generated types public.")
+	scalars := scalarFlag{}
+	flag.Var(scalars, "scalar",
+		"Use a go type for a scalar type, "+
+			"e.g. default::Money=github.com/acme/money.Money. "+
+			"Can be repeated.")
 	flag.Parse()
 
 	cfg := &cmdConfig{
 		mixedCaps: *mixedCaps,
 		pubfuncs:  *pubfuncs,
 		pubtypes:  *pubtypes,
+		scalars:   scalars,
 	}
 
 	timer := time.AfterFunc(200*time.Millisecond, func() {
@@ -251,8 +339,14 @@ func writeGoFile(
 	}
 
 	var imports []string
+	seen := make(map[string]bool)
 	for _, q := range queries {
-		imports = append(imports, q.imports...)
+		for _, imp := range q.imports {
+			if !seen[imp] {
+				seen[imp] = true
+				imports = append(imports, imp)
+			}
+		}
 	}
 
 	var buf bytes.Buffer
@@ -0,0 +1,17 @@
+package money
+
+// Money is a decimal amount of money.
+type Money struct {
+	data []byte
+}
+
+// MarshalEdgeDBDecimal implements marshal.DecimalMarshaler.
+func (m Money) MarshalEdgeDBDecimal() ([]byte, error) {
+	return m.data, nil
+}
+
+// UnmarshalEdgeDBDecimal implements marshal.DecimalUnmarshaler.
+func (m *Money) UnmarshalEdgeDBDecimal(data []byte) error {
+	m.data = append([]byte(nil), data...)
+	return nil
+}
@@ -119,8 +119,8 @@
 //	uuid                     edgedb.UUID, edgedb.OptionalUUID
 //	json                     []byte, edgedb.OptionalBytes
 //	bigint                   *big.Int, edgedb.OptionalBigInt
-//
-//	decimal                  user defined (see Custom Marshalers)
+//	decimal                  edgedb.Decimal, edgedb.OptionalDecimal
+//	ext::pgvector::vector    []float32, edgedb.OptionalVector
 //
 // Note that EdgeDB's std::duration type is represented in int64 microseconds
 // while go's time.Duration type is int64 nanoseconds. It is incorrect to cast
@@ -144,6 +144,17 @@
 //	fmt.Println(result.Missing())
 //	// Output: false
 //
+// Query results can also be decoded without defining go types. Objects and
+// named tuples can be decoded into map[string]interface{}, tuples into
+// []interface{} and any result into interface{}. Values decoded into
+// interface{} use the first go type listed above for their EdgeDB type, or
+// the go type registered with Client.RegisterScalarCodec(), missing values
+// are nil. Decoding into these types requires EdgeDB 5.0 or newer.
+//
+//	var users []map[string]interface{}
+//	err := client.Query(ctx, `SELECT User { name }`, &users)
+//	fmt.Println(users[0]["name"])
+//
 // Not all types listed above are valid query parameters.  To pass a slice of
 // scalar values use array in your query. EdgeDB doesn't currently support
 // using sets as parameters.
@@ -154,6 +165,20 @@
 // Nested structures are also not directly allowed but you can use [json]
 // instead.
 //
+// Named query parameters can be passed as a single map or struct argument.
+// Struct fields are matched to parameters by their edgedb tag or their name.
+// Use optional types for fields of optional parameters. Optional parameters
+// without a field are missing.
+//
+//	type UserFilter struct {
+//	    Name  string             `edgedb:"name"`
+//	    Email edgedb.OptionalStr `edgedb:"email"`
+//	}
+//
+//	query := `select User filter .name = <str>$name
+//	    and .email ?= <optional str>$email`
+//	err := client.Query(ctx, query, &users, UserFilter{Name: "Bob"})
+//
 // By default EdgeDB will ignore embedded structs when marshaling/unmarshaling.
 // To treat an embedded struct's fields as part of the parent struct's fields,
 // tag the embedded struct with `edgedb:"$inline"`.
@@ -172,6 +197,33 @@
 // Interfaces for user defined marshaler/unmarshalers  are documented in the
 // internal/marshal package.
 //
+// # Custom Scalars
+//
+// User defined scalar types can be mapped to go types by their name.
+// The encode and decode functions convert between the go type and a go type
+// of the scalar's base type, for example string for a scalar extending str.
+//
+//	type CountryCode struct{ code string }
+//
+//	err := client.RegisterScalarCodec(
+//	    "default::CountryCode",
+//	    func(c CountryCode) (string, error) { return c.code, nil },
+//	    func(s string) (CountryCode, error) { return CountryCode{s}, nil },
+//	)
+//
+//	var code CountryCode
+//	err = client.QuerySingle(
+//	    ctx, `SELECT <default::CountryCode>"US"`, &code)
+//
+// # database/sql
+//
+// The sqldriver package registers a database/sql driver named "edgedb".
+// DSNs are the same as for CreateClientDSN.
+//
+//	import _ "github.com/edgedb/edgedb-go/sqldriver"
+//
+//	db, err := sql.Open("edgedb", "edgedb://edgedb@localhost/main")
+//
 // [EdgeDB]: https://www.edgedb.com
 // [json]: https://www.edgedb.com/docs/edgeql/insert#bulk-inserts
 // [client connection docs]: https://www.edgedb.com/docs/clients/connection
@@ -25,13 +25,82 @@ import (
 )
 
 const (
+	// AtLeastOne means the command returns one or more results.
+	AtLeastOne = edgedb.AtLeastOne
+
+	// AtMostOne means the command returns zero or one result.
+	AtMostOne = edgedb.AtMostOne
+
+	// HostOrderInOrder tries hosts in the order they are listed.
+	HostOrderInOrder = edgedb.HostOrderInOrder
+
+	// HostOrderRandom tries hosts in a random order
+	// to spread connections across hosts.
+	HostOrderRandom = edgedb.HostOrderRandom
+
+	// KindArray is an array. Fields has one element, the array's element
+	// type.
+	KindArray = edgedb.KindArray
+
+	// KindEnum is an enum type.
+	KindEnum = edgedb.KindEnum
+
+	// KindMultiRange is a multirange. Fields has one element, the type of
+	// the multirange's ranges.
+	KindMultiRange = edgedb.KindMultiRange
+
+	// KindNamedTuple is a named tuple. Fields are the tuple's elements.
+	KindNamedTuple = edgedb.KindNamedTuple
+
+	// KindObject is an object or an input shape. Fields are the object's
+	// properties and links.
+	KindObject = edgedb.KindObject
+
+	// KindRange is a range. Fields has one element, the range's element
+	// type.
+	KindRange = edgedb.KindRange
+
+	// KindRecord is an SQL query result row. Fields are the row's columns.
+	KindRecord = edgedb.KindRecord
+
+	// KindScalar is a scalar type.
+	KindScalar = edgedb.KindScalar
+
+	// KindSet is a set of values. Fields has one element, the set's element
+	// type.
+	KindSet = edgedb.KindSet
+
+	// KindTuple is an unnamed tuple. Fields are the tuple's elements.
+	KindTuple = edgedb.KindTuple
+
+	// Many means the command returns zero or more results.
+	Many = edgedb.Many
+
 	// NetworkError indicates that the transaction was interupted
 	// by a network error.
 	NetworkError = edgedb.NetworkError
 
+	// NoResult means the command does not return a result.
+	NoResult = edgedb.NoResult
+
+	// One means the command returns exactly one result.
+	One = edgedb.One
+
 	// Serializable is the only isolation level
 	Serializable = edgedb.Serializable
 
+	// ServerLogDebug is the severity of debug messages.
+	ServerLogDebug = edgedb.ServerLogDebug
+
+	// ServerLogInfo is the severity of informational messages.
+	ServerLogInfo = edgedb.ServerLogInfo
+
+	// ServerLogNotice is the severity of notices.
+	ServerLogNotice = edgedb.ServerLogNotice
+
+	// ServerLogWarning is the severity of warnings.
+	ServerLogWarning = edgedb.ServerLogWarning
+
 	// TLSModeDefault makes security mode inferred from other options
 	TLSModeDefault = edgedb.TLSModeDefault
 
@@ -51,13 +120,42 @@ const (
 )
 
 type (
+	// Batch is a list of queries that are sent to the server together by
+	// Client.SendBatch() or Tx.SendBatch(). The zero value is an empty batch.
+	Batch = edgedb.Batch
+
+	// Cardinality is the result cardinality for a command.
+	Cardinality = edgedb.Cardinality
+
 	// Client is a connection pool and is safe for concurrent use.
 	Client = edgedb.Client
 
+	// ConnectionCredentials are the credentials used to authenticate a new
+	// connection. Empty fields are replaced with the values resolved from
+	// Options, the DSN, environment variables etc.
+	ConnectionCredentials = edgedb.ConnectionCredentials
+
+	// CredentialsProvider supplies credentials for new connections so that
+	// rotated secrets are picked up without recreating the client. It must be
+	// safe for concurrent use.
+	CredentialsProvider = edgedb.CredentialsProvider
+
 	// DateDuration represents the elapsed time between two dates in a fuzzy human
 	// way.
 	DateDuration = edgedbtypes.DateDuration
 
+	// Decimal is an arbitrary precision decimal number. It is stored as an
+	// unscaled integer and a scale, the number of digits after the decimal
+	// point. The zero value is 0.
+	Decimal = edgedbtypes.Decimal
+
+	// DescriptorField is an element of a TypeDescriptor.
+	DescriptorField = edgedb.DescriptorField
+
+	// DialFunc connects to an address on the named network. It has the same
+	// signature as net.Dialer.DialContext.
+	DialFunc = edgedb.DialFunc
+
 	// Duration represents the elapsed time between two instants
 	// as an int64 microsecond count.
 	Duration = edgedbtypes.Duration
@@ -75,6 +173,10 @@ type (
 	// that can run queries on an EdgeDB database.
 	Executor = edgedb.Executor
 
+	// HostOrder determines the order in which a client tries to connect to
+	// multiple hosts.
+	HostOrder = edgedb.HostOrder
+
 	// IsolationLevel documentation can be found here
 	// https://www.edgedb.com/docs/reference/edgeql/tx_start#parameters
 	IsolationLevel = edgedb.IsolationLevel
@@ -94,6 +196,11 @@ type (
 	// Memory represents memory in bytes.
 	Memory = edgedbtypes.Memory
 
+	// Metrics is notified of connection pool events. Methods are called
+	// synchronously by the client so they should return quickly, and they must
+	// be safe for concurrent use.
+	Metrics = edgedb.Metrics
+
 	// ModuleAlias is an alias name and module name pair.
 	ModuleAlias = edgedb.ModuleAlias
 
@@ -126,6 +233,10 @@ type (
 	// out parameters when a shape field is not required.
 	OptionalDateTime = edgedbtypes.OptionalDateTime
 
+	// OptionalDecimal is an optional Decimal. Optional types must be used for
+	// out parameters when a shape field is not required.
+	OptionalDecimal = edgedbtypes.OptionalDecimal
+
 	// OptionalDuration is an optional Duration. Optional types must be used for
 	// out parameters when a shape field is not required.
 	OptionalDuration = edgedbtypes.OptionalDuration
@@ -206,9 +317,25 @@ type (
 	// parameters when a shape field is not required.
 	OptionalUUID = edgedbtypes.OptionalUUID
 
+	// OptionalVector is an optional ext::pgvector::vector. Optional types must be
+	// used for out parameters when a shape field is not required.
+	OptionalVector = edgedbtypes.OptionalVector
+
 	// Options for connecting to an EdgeDB server
 	Options = edgedb.Options
 
+	// PoolStats are statistics about a client's connection pool.
+	PoolStats = edgedb.PoolStats
+
+	// PreparedQuery is a query that has been parsed by the server and whose
+	// codecs have been built for a Go type. Running a prepared query skips
+	// parsing and codec cache lookups. Use Client.Prepare() to get a
+	// PreparedQuery. It is safe for concurrent use.
+	PreparedQuery = edgedb.PreparedQuery
+
+	// QueryDescription describes a query's arguments and results.
+	QueryDescription = edgedb.QueryDescription
+
 	// RangeDateTime is an interval of time.Time values.
 	RangeDateTime = edgedbtypes.RangeDateTime
 
@@ -234,6 +361,14 @@ type (
 	// human way.
 	RelativeDuration = edgedbtypes.RelativeDuration
 
+	// ResolvedConfig is the connection configuration that a client created with
+	// the same DSN and Options would use. It is meant for logging and
+	// troubleshooting and does not contain secrets.
+	ResolvedConfig = edgedb.ResolvedConfig
+
+	// ResolvedValue is a connection parameter and where it came from.
+	ResolvedValue = edgedb.ResolvedValue
+
 	// RetryBackoff returns the duration to wait after the nth attempt
 	// before making the next attempt when retrying a transaction.
 	RetryBackoff = edgedb.RetryBackoff
@@ -251,6 +386,43 @@ type (
 	// methods. See Client.Tx() for details.
 	RetryRule = edgedb.RetryRule
 
+	// Rows is an iterator over the results of a query started with QueryIter().
+	// Results are decoded one at a time as they are received from the server
+	// instead of being collected into a slice, so large results can be processed
+	// without holding all of them in memory.
+	//
+	// Rows holds a connection until it has been iterated to the end or closed.
+	// Always call Close() after a successful QueryIter() call.
+	//
+	//	rows, err := client.QueryIter(ctx, "SELECT User { name }")
+	//	if err != nil {
+	//	    return err
+	//	}
+	//	defer rows.Close()
+	//
+	//	for rows.Next() {
+	//	    var user User
+	//	    if err := rows.Scan(&user); err != nil {
+	//	        return err
+	//	    }
+	//	    ...
+	//	}
+	//
+	//	return rows.Err()
+	Rows = edgedb.Rows
+
+	// ServerLogHandler is invoked with log messages sent by the server. Messages
+	// received while a query is running are passed to the handler of the client
+	// or transaction that ran the query, other messages are passed to the handler
+	// from Options.ServerLogHandler.
+	ServerLogHandler = edgedb.ServerLogHandler
+
+	// ServerLogMessage is a log message sent by the server.
+	ServerLogMessage = edgedb.ServerLogMessage
+
+	// ServerLogSeverity is the severity of a ServerLogMessage.
+	ServerLogSeverity = edgedb.ServerLogSeverity
+
 	// TLSOptions contains the parameters needed to configure TLS on EdgeDB
 	// server connections.
 	TLSOptions = edgedb.TLSOptions
@@ -267,6 +439,12 @@ type (
 	// TxOptions configures how transactions behave.
 	TxOptions = edgedb.TxOptions
 
+	// TypeDescriptor describes a query's argument or result type.
+	TypeDescriptor = edgedb.TypeDescriptor
+
+	// TypeKind is the kind of type described by a TypeDescriptor.
+	TypeKind = edgedb.TypeKind
+
 	// UUID is a universally unique identifier
 	// https://www.edgedb.com/docs/stdlib/uuid
 	UUID = edgedbtypes.UUID
@@ -293,16 +471,32 @@ var (
 	// The following options are recognized: host, port, user, database, password.
 	CreateClientDSN = edgedb.CreateClientDSN
 
+	// DecimalFromBigInt returns v as a Decimal.
+	DecimalFromBigInt = edgedbtypes.DecimalFromBigInt
+
+	// DecimalFromRat returns r as a Decimal. An error is returned
+	// if r can not be represented exactly with a finite number of decimal digits,
+	// for example 1/3.
+	DecimalFromRat = edgedbtypes.DecimalFromRat
+
 	// DurationFromNanoseconds creates a Duration represented as microseconds
 	// from a [time.Duration] represented as nanoseconds.
 	DurationFromNanoseconds = edgedbtypes.DurationFromNanoseconds
 
+	// LogServerMessages is an edgedb.ServerLogHandler that logs server messages
+	// with the standard library's log package.
+	LogServerMessages = edgedb.LogServerMessages
+
 	// LogWarnings is an edgedb.WarningHandler that logs warnings.
 	LogWarnings = edgedb.LogWarnings
 
 	// NewDateDuration returns a new DateDuration
 	NewDateDuration = edgedbtypes.NewDateDuration
 
+	// NewDecimal returns the Decimal unscaled * 10^-scale.
+	// unscaled is copied and is not modified.
+	NewDecimal = edgedbtypes.NewDecimal
+
 	// NewLocalDate returns a new LocalDate
 	NewLocalDate = edgedbtypes.NewLocalDate
 
@@ -332,6 +526,10 @@ var (
 	// OptionalDateTime with its value set to v.
 	NewOptionalDateTime = edgedbtypes.NewOptionalDateTime
 
+	// NewOptionalDecimal is a convenience function for creating an
+	// OptionalDecimal with its value set to v.
+	NewOptionalDecimal = edgedbtypes.NewOptionalDecimal
+
 	// NewOptionalDuration is a convenience function for creating an
 	// OptionalDuration with its value set to v.
 	NewOptionalDuration = edgedbtypes.NewOptionalDuration
@@ -412,6 +610,10 @@ var (
 	// its value set to v.
 	NewOptionalUUID = edgedbtypes.NewOptionalUUID
 
+	// NewOptionalVector is a convenience function for creating an OptionalVector
+	// with its value set to v.
+	NewOptionalVector = edgedbtypes.NewOptionalVector
+
 	// NewRangeDateTime creates a new RangeDateTime value.
 	NewRangeDateTime = edgedbtypes.NewRangeDateTime
 
@@ -445,9 +647,21 @@ var (
 	// NewTxOptions returns the default TxOptions value.
 	NewTxOptions = edgedb.NewTxOptions
 
+	// ParseDecimal parses a decimal number like 123.45, -0.001 or 1.5e-3.
+	// The number of digits after the decimal point, including trailing zeros,
+	// is kept as the decimal's scale. Numbers with more than 131072 digits
+	// before the decimal point or more than 16383 digits after it are rejected.
+	ParseDecimal = edgedbtypes.ParseDecimal
+
 	// ParseUUID parses s into a UUID or returns an error.
 	ParseUUID = edgedbtypes.ParseUUID
 
+	// ResolveConfig resolves the connection configuration from dsn, opts,
+	// environment variables, credentials files and the project's edgedb.toml
+	// the same way CreateClientDSN does, and reports the source of each
+	// parameter. It does not connect to the server.
+	ResolveConfig = edgedb.ResolveConfig
+
 	// WarningsAsErrors is an edgedb.WarningHandler that returns warnings as
 	// errors.
 	WarningsAsErrors = edgedb.WarningsAsErrors
@@ -0,0 +1,366 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+)
+
+// Batch is a list of queries that are sent to the server together by
+// Client.SendBatch() or Tx.SendBatch(). The zero value is an empty batch.
+type Batch struct {
+	items []batchItem
+}
+
+type batchItem struct {
+	method string
+	cmd    string
+	out    interface{}
+	args   []interface{}
+}
+
+// Execute adds an EdgeQL command to the batch.
+func (b *Batch) Execute(cmd string, args ...interface{}) {
+	b.items = append(b.items, batchItem{
+		method: "Execute",
+		cmd:    cmd,
+		args:   args,
+	})
+}
+
+// Query adds a query to the batch.
+// Its results are decoded into out when the batch is sent.
+func (b *Batch) Query(cmd string, out interface{}, args ...interface{}) {
+	b.items = append(b.items, batchItem{
+		method: "Query",
+		cmd:    cmd,
+		out:    out,
+		args:   args,
+	})
+}
+
+// QuerySingle adds a singleton-returning query to the batch.
+// Its result is decoded into out when the batch is sent.
+func (b *Batch) QuerySingle(cmd string, out interface{}, args ...interface{}) {
+	b.items = append(b.items, batchItem{
+		method: "QuerySingle",
+		cmd:    cmd,
+		out:    out,
+		args:   args,
+	})
+}
+
+// Len returns the number of queries in the batch.
+func (b *Batch) Len() int {
+	return len(b.items)
+}
+
+// pipeline is a batch's queries ready to be sent on a connection.
+type pipeline struct {
+	// queries[i] is nil if errs[i] was set before the batch was sent.
+	queries          []*query
+	outs             []interface{}
+	errs             []error
+	serverLogHandler ServerLogHandler
+
+	// next is the index of the first query that has not finished running.
+	next int
+}
+
+func (b *Batch) pipeline(
+	capabilities uint64,
+	state map[string]interface{},
+	warningHandler WarningHandler,
+	serverLogHandler ServerLogHandler,
+	compilation compilationOptions,
+) *pipeline {
+	p := &pipeline{
+		queries:          make([]*query, len(b.items)),
+		outs:             make([]interface{}, len(b.items)),
+		errs:             make([]error, len(b.items)),
+		serverLogHandler: serverLogHandler,
+	}
+
+	for i, item := range b.items {
+		q, err := newQuery(
+			item.method,
+			item.cmd,
+			item.args,
+			capabilities,
+			state,
+			item.out,
+			true,
+			warningHandler,
+			serverLogHandler,
+			compilation,
+		)
+		if err != nil {
+			p.errs[i] = err
+			continue
+		}
+
+		p.queries[i] = q
+		p.outs[i] = item.out
+	}
+
+	return p
+}
+
+// fail sets err for the queries that have not finished running
+// and don't have an error yet.
+func (p *pipeline) fail(err error) {
+	for i := p.next; i < len(p.queries); i++ {
+		if p.queries[i] != nil && p.errs[i] == nil {
+			p.errs[i] = err
+		}
+	}
+}
+
+// SendBatch runs the queries in b on a single connection. All of the queries
+// are sent before waiting for their results, instead of waiting for each
+// result before sending the next query. Each query runs in its own implicit
+// transaction and a failed query does not stop the queries after it.
+// To run a batch in a single transaction call Tx.SendBatch() in Client.Tx().
+//
+// errs has one element for each query in b, errs[i] is the error from the
+// i-th query or nil if it succeeded. err is not nil if the batch could not be
+// sent, for example if a connection could not be acquired.
+func (p *Client) SendBatch(
+	ctx context.Context,
+	b *Batch,
+) (errs []error, err error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pl := b.pipeline(
+		conn.capabilities1pX(),
+		copyState(p.state),
+		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
+	)
+
+	err = conn.batch(ctx, pl)
+	return pl.errs, firstError(err, p.release(conn, err))
+}
+
+// SendBatch runs the queries in b in the transaction. All of the queries are
+// sent before waiting for their results. If a query fails the transaction
+// can not be committed and the queries after it fail too.
+//
+// errs has one element for each query in b, errs[i] is the error from the
+// i-th query or nil if it succeeded. err is not nil if the batch could not be
+// sent.
+func (t *Tx) SendBatch(
+	ctx context.Context,
+	b *Batch,
+) (errs []error, err error) {
+	if e := t.assertStarted("SendBatch"); e != nil {
+		return nil, e
+	}
+
+	pl := b.pipeline(
+		t.capabilities1pX(),
+		t.state,
+		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
+	)
+
+	err = t.borrowableConn.batch(ctx, pl)
+	return pl.errs, err
+}
+
+func (c *borrowableConn) batch(ctx context.Context, p *pipeline) error {
+	if e := c.assertUnborrowed(); e != nil {
+		return e
+	}
+
+	return c.conn.batch(ctx, p)
+}
+
+func (c *reconnectingConn) batch(ctx context.Context, p *pipeline) error {
+	if e := c.ensureConnection(ctx); e != nil {
+		return e
+	}
+
+	return c.borrowableConn.batch(ctx, p)
+}
+
+func (c *protocolConnection) batch(ctx context.Context, p *pipeline) error {
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	restore := c.useServerLogHandler(p.serverLogHandler)
+	stop := c.cancelOnDone(ctx)
+	if c.protocolVersion.GTE(protocolVersion2p0) {
+		err = c.execBatch2pX(r, p)
+	} else {
+		err = c.execBatchSequential(r, p)
+	}
+	stop()
+	restore()
+
+	err = c.cancelledError(ctx, err)
+	if err != nil {
+		p.fail(err)
+	}
+
+	return firstError(err, c.releaseReader(r))
+}
+
+// execBatchSequential runs a batch one query at a time. It is used for
+// servers that are too old for pipelining.
+func (c *protocolConnection) execBatchSequential(
+	r *buff.Reader,
+	p *pipeline,
+) error {
+	for i, q := range p.queries {
+		p.next = i
+		if q == nil {
+			continue
+		}
+
+		var err error
+		switch {
+		case c.protocolVersion.GTE(protocolVersion1p0):
+			err = c.execGranularFlow1pX(r, q)
+		case q.method == "Execute":
+			err = c.execScriptFlow(r, q)
+		default:
+			err = c.execGranularFlow0pX(r, q)
+		}
+
+		if r.Err != nil || isClientConnectionError(err) {
+			return err
+		}
+
+		p.errs[i] = queryResult(q, p.outs[i], err)
+	}
+
+	p.next = len(p.queries)
+	return nil
+}
+
+// execBatch2pX sends an Execute message followed by a Sync message for each
+// query before reading any of the results. The server skips messages after
+// an error until the next Sync, so a Sync after each query lets the remaining
+// queries run after an error.
+func (c *protocolConnection) execBatch2pX(
+	r *buff.Reader,
+	p *pipeline,
+) error {
+	cdcs := make([]*codecPair, len(p.queries))
+	for i, q := range p.queries {
+		if q == nil {
+			continue
+		}
+
+		var err error
+		cdcs[i], err = c.batchCodecs2pX(r, q)
+		if r.Err != nil || isClientConnectionError(err) {
+			return err
+		}
+
+		p.errs[i] = err
+	}
+
+	var buf []byte
+	for i, q := range p.queries {
+		if cdcs[i] == nil {
+			continue
+		}
+
+		w := buff.NewWriter(nil)
+		err := c.encodeExecute2pX(w, q, cdcs[i].in, cdcs[i].out.DescriptorID())
+		if err != nil {
+			p.errs[i] = err
+			cdcs[i] = nil
+			continue
+		}
+
+		w.BeginMessage(uint8(Sync))
+		w.EndMessage()
+		buf = append(buf, w.Unwrap()...)
+	}
+
+	if len(buf) == 0 {
+		p.next = len(p.queries)
+		return nil
+	}
+
+	// Results are read while the queries are being written so that
+	// neither side blocks on a full socket buffer.
+	written := make(chan error, 1)
+	go func() { written <- c.soc.WriteAll(buf) }()
+
+	for i, q := range p.queries {
+		p.next = i
+		if cdcs[i] == nil {
+			continue
+		}
+
+		err := c.decodeExecuteResult2pX(r, q, cdcs[i])
+		if r.Err != nil || isClientConnectionError(err) {
+			_ = c.soc.Close()
+			<-written
+			return err
+		}
+
+		p.errs[i] = queryResult(q, p.outs[i], err)
+	}
+
+	p.next = len(p.queries)
+	if e := <-written; e != nil {
+		return &clientConnectionClosedError{err: e}
+	}
+
+	return nil
+}
+
+// batchCodecs2pX returns the codecs for q,
+// parsing q if the codecs are not cached.
+func (c *protocolConnection) batchCodecs2pX(
+	r *buff.Reader,
+	q *query,
+) (*codecPair, error) {
+	if ids, ok := c.getCachedTypeIDs(q); ok {
+		cdcs, err := c.codecsFromIDsV2(ids, q)
+		if err != nil || cdcs != nil {
+			return cdcs, err
+		}
+	}
+
+	desc, err := c.parse2pX(r, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.codecsFromDescriptors2pX(q, desc)
+}
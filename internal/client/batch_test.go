@@ -0,0 +1,129 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+func TestSendBatch(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		b        Batch
+		many     []int64
+		single   string
+		optional types.OptionalStr
+		missing  string
+	)
+
+	b.Execute("SELECT 1")
+	b.Query("SELECT {1, 2, 3}", &many)
+	b.QuerySingle("SELECT <str>$0", &single, "hello")
+	b.QuerySingle("SELECT 1 / 0", &single)
+	b.QuerySingle("SELECT <str>{}", &optional)
+	b.QuerySingle("SELECT <str>{}", &missing)
+	b.Query("SELECT 1", nil)
+	require.Equal(t, 7, b.Len())
+
+	errs, err := client.SendBatch(ctx, &b)
+	require.NoError(t, err)
+	require.Equal(t, 7, len(errs))
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Equal(t, []int64{1, 2, 3}, many)
+	assert.NoError(t, errs[2])
+	assert.Equal(t, "hello", single)
+
+	var divErr Error
+	require.True(t, errors.As(errs[3], &divErr))
+	assert.True(t, divErr.Category(DivisionByZeroError))
+
+	assert.NoError(t, errs[4])
+	_, ok := optional.Get()
+	assert.False(t, ok)
+
+	var noData Error
+	require.True(t, errors.As(errs[5], &noData))
+	assert.True(t, noData.Category(NoDataError))
+
+	// Invalid arguments only fail their own query.
+	var argErr Error
+	require.True(t, errors.As(errs[6], &argErr))
+	assert.True(t, argErr.Category(InterfaceError))
+
+	// The connection is usable after the batch.
+	var result int64
+	require.NoError(t, client.QuerySingle(ctx, "SELECT 1", &result))
+}
+
+func TestSendBatchManyQueries(t *testing.T) {
+	ctx := context.Background()
+
+	var b Batch
+	results := make([]int64, 500)
+	for i := range results {
+		b.QuerySingle("SELECT <int64>$0 * 2", &results[i], int64(i))
+	}
+
+	errs, err := client.SendBatch(ctx, &b)
+	require.NoError(t, err)
+
+	for i, e := range errs {
+		require.NoError(t, e)
+		assert.Equal(t, int64(i*2), results[i])
+	}
+}
+
+func TestTxSendBatch(t *testing.T) {
+	ctx := context.Background()
+
+	var count int64
+	query := "SELECT count(TxTest FILTER .name = 'Test Batch')"
+	require.NoError(t, client.QuerySingle(ctx, query, &count))
+	require.Equal(t, int64(0), count)
+
+	err := client.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+		var b Batch
+		b.Execute("INSERT TxTest {name := 'Test Batch'}")
+		b.Execute("INSERT TxTest {name := 'Test Batch'}")
+		b.Execute("SELECT 1 / 0")
+		b.QuerySingle(query, &count)
+
+		errs, e := tx.SendBatch(ctx, &b)
+		require.NoError(t, e)
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		assert.Error(t, errs[2])
+		assert.Error(t, errs[3])
+
+		return errs[2]
+	})
+	require.Error(t, err)
+
+	// The transaction was rolled back.
+	require.NoError(t, client.QuerySingle(ctx, query, &count))
+	assert.Equal(t, int64(0), count)
+}
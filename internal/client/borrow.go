@@ -19,6 +19,7 @@ package edgedb
 import (
 	"context"
 	"fmt"
+	"io"
 )
 
 type borrowableConn struct {
@@ -40,13 +41,18 @@ func (c *borrowableConn) borrow(reason string) (*protocolConnection, error) {
 			msg: "The transaction is borrowed for a subtransaction. " +
 				"Use the methods on the subtransaction object instead.",
 		}
+	case "rows":
+		return nil, &interfaceError{
+			msg: "The connection is borrowed by open Rows. " +
+				"Close the Rows before running another query.",
+		}
 	default:
 		return nil, &interfaceError{msg: fmt.Sprintf(
 			"existing borrow reason is unexpected: %q", c.reason)}
 	}
 
 	switch reason {
-	case "transaction", "subtransaction":
+	case "transaction", "subtransaction", "rows":
 		c.reason = reason
 		return c.conn, nil
 	default:
@@ -78,6 +84,11 @@ func (c *borrowableConn) assertUnborrowed() error {
 			msg: "The transaction is borrowed for a subtransaction. " +
 				"Use the methods on the subtransaction object instead.",
 		}
+	case "rows":
+		return &interfaceError{
+			msg: "The connection is borrowed by open Rows. " +
+				"Close the Rows before running another query.",
+		}
 	default:
 		return &interfaceError{msg: fmt.Sprintf(
 			"existing borrow reason is unexpected: %q", c.reason)}
@@ -103,3 +114,30 @@ func (c *borrowableConn) granularFlow(ctx context.Context, q *query) error {
 
 	return c.conn.granularFlow(ctx, q)
 }
+
+func (c *borrowableConn) queryIter(
+	ctx context.Context,
+	q *query,
+) (*Rows, error) {
+	if e := c.assertUnborrowed(); e != nil {
+		return nil, e
+	}
+
+	return c.conn.queryIter(ctx, q)
+}
+
+func (c *borrowableConn) dump(ctx context.Context, out io.Writer) error {
+	if e := c.assertUnborrowed(); e != nil {
+		return e
+	}
+
+	return c.conn.dump(ctx, out)
+}
+
+func (c *borrowableConn) restore(ctx context.Context, in io.Reader) error {
+	if e := c.assertUnborrowed(); e != nil {
+		return e
+	}
+
+	return c.conn.restore(ctx, in)
+}
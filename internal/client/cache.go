@@ -73,6 +73,9 @@ type queryKey struct {
 	fmt     Format
 	expCard Cardinality
 	outType reflect.Type
+
+	// compilation changes the query's output type.
+	compilation compilationOptions
 }
 
 func makeKey(q *query) queryKey {
@@ -82,6 +85,8 @@ func makeKey(q *query) queryKey {
 		fmt:     q.fmt,
 		expCard: q.expCard,
 		outType: q.outType,
+
+		compilation: q.compilation,
 	}
 }
 
@@ -127,7 +132,7 @@ func (c *protocolConnection) cacheCapabilities1pX(
 	c.capabilitiesCache.Put(makeKey(q), capabilities)
 }
 
-func (c *reconnectingConn) getCachedCapabilities(q *query) (uint64, bool) {
+func (c cacheCollection) getCachedCapabilities(q *query) (uint64, bool) {
 	if val, ok := c.capabilitiesCache.Get(makeKey(q)); ok {
 		x := val.(uint64)
 		return x, true
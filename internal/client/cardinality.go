@@ -23,9 +23,18 @@ type Cardinality uint8
 
 // Cardinalities
 const (
-	NoResult   Cardinality = 0x6e
-	AtMostOne  Cardinality = 0x6f
-	One        Cardinality = 0x41
-	Many       Cardinality = 0x6d
+	// NoResult means the command does not return a result.
+	NoResult Cardinality = 0x6e
+
+	// AtMostOne means the command returns zero or one result.
+	AtMostOne Cardinality = 0x6f
+
+	// One means the command returns exactly one result.
+	One Cardinality = 0x41
+
+	// Many means the command returns zero or more results.
+	Many Cardinality = 0x6d
+
+	// AtLeastOne means the command returns one or more results.
 	AtLeastOne Cardinality = 0x4d
 )
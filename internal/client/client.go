@@ -19,15 +19,21 @@ package edgedb
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/edgedb/edgedb-go/internal/cache"
+	"github.com/edgedb/edgedb-go/internal/codecs"
 	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
 )
 
-const defaultIdleConnectionTimeout = 30 * time.Second
+const (
+	defaultIdleConnectionTimeout = 30 * time.Second
+	defaultHealthCheckInterval   = 30 * time.Second
+	healthCheckTimeout           = 10 * time.Second
+)
 
 func max(a, b int) int {
 	if a > b {
@@ -59,7 +65,32 @@ type Client struct {
 	cacheCollection
 	state map[string]interface{}
 
-	warningHandler WarningHandler
+	warningHandler   WarningHandler
+	serverLogHandler ServerLogHandler
+	compilation      compilationOptions
+
+	stats *poolStats
+
+	minIdleConns        int
+	maxConnLifetime     time.Duration
+	healthCheckInterval time.Duration
+
+	// done is closed when the client is closed
+	// to stop the background health checks.
+	done     chan struct{}
+	stopOnce *sync.Once
+
+	// active are the connections that are currently acquired.
+	active *activeConns
+
+	// hosts tracks failures when there are multiple hosts.
+	hosts *hostList
+
+	// replicas is the pool of connections to the read replicas.
+	replicas *Client
+
+	// readOnlyRouting sends read only queries to replicas.
+	readOnlyRouting bool
 }
 
 // CreateClient returns a new client. The client connects lazily. Call
@@ -88,28 +119,83 @@ func CreateClientDSN(_ context.Context, dsn string, opts Options) (*Client, erro
 		warningHandler = opts.WarningHandler
 	}
 
+	serverLogHandler := LogServerMessages
+	if opts.ServerLogHandler != nil {
+		serverLogHandler = opts.ServerLogHandler
+	}
+	cfg.serverLogHandler = serverLogHandler
+	cfg.credentialsProvider = opts.CredentialsProvider
+
+	caches := cacheCollection{
+		serverSettings:    cfg.serverSettings,
+		typeIDCache:       cache.New(1_000),
+		inCodecCache:      cache.New(1_000),
+		outCodecCache:     cache.New(1_000),
+		capabilitiesCache: cache.New(1_000),
+		customScalars:     codecs.NewScalarRegistry(),
+	}
+
+	p := newClient(cfg, &opts, caches, warningHandler)
+	if len(cfg.readReplicas) != 0 {
+		// The replicas have the same schema as the primary
+		// so they share its caches.
+		p.replicas = newClient(cfg.replicaConfig(), &opts, caches,
+			warningHandler)
+	}
+
+	return p, nil
+}
+
+func newClient(
+	cfg *connConfig,
+	opts *Options,
+	caches cacheCollection,
+	warningHandler WarningHandler,
+) *Client {
+	healthCheckInterval := opts.HealthCheckInterval
+	if healthCheckInterval <= 0 &&
+		(opts.MinIdleConns > 0 || opts.MaxConnLifetime > 0) {
+		healthCheckInterval = defaultHealthCheckInterval
+	}
+
+	var hosts *hostList
+	if len(cfg.fallbackAddrs) != 0 {
+		hosts = newHostList(cfg.addrs(), cfg.hostOrder)
+	}
+
 	False := false
 	p := &Client{
-		isClosed:             &False,
-		isClosedMutex:        &sync.RWMutex{},
-		cfg:                  cfg,
-		txOpts:               NewTxOptions(),
-		concurrency:          int(opts.Concurrency),
-		freeConns:            make(chan func() *transactableConn, 1),
+		isClosed:      &False,
+		isClosedMutex: &sync.RWMutex{},
+		cfg:           cfg,
+		txOpts:        NewTxOptions(),
+		concurrency:   int(opts.Concurrency),
+		freeConns: make(
+			chan func() *transactableConn,
+			max(1, int(opts.MinIdleConns)),
+		),
 		potentialConnsMutext: &sync.Mutex{},
 		retryOpts:            NewRetryOptions(),
-		cacheCollection: cacheCollection{
-			serverSettings:    cfg.serverSettings,
-			typeIDCache:       cache.New(1_000),
-			inCodecCache:      cache.New(1_000),
-			outCodecCache:     cache.New(1_000),
-			capabilitiesCache: cache.New(1_000),
-		},
-		state:          make(map[string]interface{}),
-		warningHandler: warningHandler,
+		cacheCollection:      caches,
+		state:                make(map[string]interface{}),
+		warningHandler:       warningHandler,
+		serverLogHandler:     cfg.serverLogHandler,
+		stats:                &poolStats{metrics: opts.Metrics},
+
+		minIdleConns:        int(opts.MinIdleConns),
+		maxConnLifetime:     opts.MaxConnLifetime,
+		healthCheckInterval: healthCheckInterval,
+		done:                make(chan struct{}),
+		stopOnce:            &sync.Once{},
+		active:              &activeConns{},
+		hosts:               hosts,
 	}
 
-	return p, nil
+	if p.healthCheckInterval > 0 {
+		go p.maintain()
+	}
+
+	return p
 }
 
 func (p *Client) newConn(ctx context.Context) (*transactableConn, error) {
@@ -119,6 +205,7 @@ func (p *Client) newConn(ctx context.Context) (*transactableConn, error) {
 		reconnectingConn: &reconnectingConn{
 			cfg:             p.cfg,
 			cacheCollection: p.cacheCollection,
+			hosts:           p.hosts,
 		},
 	}
 
@@ -126,6 +213,12 @@ func (p *Client) newConn(ctx context.Context) (*transactableConn, error) {
 		return nil, err
 	}
 
+	if p.maxConnLifetime > 0 {
+		lifetime := float64(p.maxConnLifetime)
+		jitter := time.Duration(rnd.Float64() * 0.1 * lifetime)
+		conn.expiresAt = time.Now().Add(p.maxConnLifetime - jitter)
+	}
+
 	return &conn, nil
 }
 
@@ -137,6 +230,8 @@ func (p *Client) acquire(ctx context.Context) (*transactableConn, error) {
 		return nil, &interfaceError{msg: "client closed"}
 	}
 
+	start := time.Now()
+
 	p.potentialConnsMutext.Lock()
 	if p.potentialConns == nil {
 		conn, err := p.newConn(ctx)
@@ -144,6 +239,7 @@ func (p *Client) acquire(ctx context.Context) (*transactableConn, error) {
 			p.potentialConnsMutext.Unlock()
 			return nil, err
 		}
+		p.stats.connected()
 
 		if p.concurrency == 0 {
 			// The user did not set Concurrency in provided Options.
@@ -163,6 +259,7 @@ func (p *Client) acquire(ctx context.Context) (*transactableConn, error) {
 		}
 
 		p.potentialConnsMutext.Unlock()
+		p.acquired(conn, start)
 		return conn, nil
 	}
 	p.potentialConnsMutext.Unlock()
@@ -171,6 +268,8 @@ func (p *Client) acquire(ctx context.Context) (*transactableConn, error) {
 	select {
 	case <-ctx.Done():
 		return nil, fmt.Errorf("edgedb: %w", ctx.Err())
+	case <-p.done:
+		return nil, &interfaceError{msg: "client closed"}
 	default:
 	}
 
@@ -179,16 +278,21 @@ func (p *Client) acquire(ctx context.Context) (*transactableConn, error) {
 	case acquireIfNotTimedout := <-p.freeConns:
 		conn := acquireIfNotTimedout()
 		if conn != nil {
+			p.acquired(conn, start)
 			return conn, nil
 		}
 	default:
 	}
 
+	p.stats.waiting(1)
+	defer p.stats.waiting(-1)
+
 	for {
 		select {
 		case acquireIfNotTimedout := <-p.freeConns:
 			conn := acquireIfNotTimedout()
 			if conn != nil {
+				p.acquired(conn, start)
 				return conn, nil
 			}
 			continue
@@ -198,24 +302,74 @@ func (p *Client) acquire(ctx context.Context) (*transactableConn, error) {
 				p.potentialConns <- struct{}{}
 				return nil, err
 			}
+			p.stats.connected()
+			p.acquired(conn, start)
 			return conn, nil
 		case <-ctx.Done():
 			return nil, fmt.Errorf("edgedb: %w", ctx.Err())
+		case <-p.done:
+			return nil, &interfaceError{msg: "client closed"}
 		}
 	}
 }
 
+func (p *Client) acquired(conn *transactableConn, start time.Time) {
+	p.active.add(conn)
+	p.stats.acquired(time.Since(start))
+}
+
 type systemConfig struct {
 	ID                 types.OptionalUUID     `edgedb:"id"`
 	SessionIdleTimeout types.OptionalDuration `edgedb:"session_idle_timeout"`
 }
 
 func (p *Client) release(conn *transactableConn, err error) error {
+	p.stats.released()
+
+	// The connection is still active until it is closed or idle
+	// so that Shutdown waits for it.
+	defer p.active.remove(conn)
+
 	if isClientConnectionError(err) {
-		p.potentialConns <- struct{}{}
-		return conn.Close()
+		return p.closeConn(conn, closedErrored, err)
 	}
 
+	// The socket is closed when a query is stopped because its context was
+	// done. Replace the connection instead of keeping it idle.
+	if conn.conn != nil && conn.conn.isClosed() {
+		return p.closeConn(conn, closedErrored, err)
+	}
+
+	if conn.expired() {
+		return p.closeConn(conn, closedExpired, nil)
+	}
+
+	select {
+	case <-p.done:
+		// the client is closing so the connection is not needed.
+		return p.closeConn(conn, closedClient, nil)
+	default:
+	}
+
+	conn.idleSince = time.Now()
+	return p.putIdle(conn)
+}
+
+// closeConn closes a connection that is not in use
+// and returns its capacity to the pool.
+func (p *Client) closeConn(
+	conn *transactableConn,
+	reason closeReason,
+	err error,
+) error {
+	p.potentialConns <- struct{}{}
+	p.stats.closed(reason, err)
+	return conn.Close()
+}
+
+// putIdle makes an idle connection available to be acquired.
+// The connection is closed if there are already enough idle connections.
+func (p *Client) putIdle(conn *transactableConn) error {
 	timeout := defaultIdleConnectionTimeout
 	if t, ok := conn.conn.systemConfig.SessionIdleTimeout.Get(); ok {
 		timeout = time.Duration(1_000 * t)
@@ -228,11 +382,14 @@ func (p *Client) release(conn *transactableConn, err error) error {
 			return nil
 		default:
 			// we have MinConns idle so no need to keep this connection.
-			p.potentialConns <- struct{}{}
-			return conn.Close()
+			return p.closeConn(conn, closedIdle, nil)
 		}
 	}
 
+	// the connection may have already been idle
+	// if it is being returned by a health check.
+	timeout -= time.Since(conn.idleSince)
+
 	cancel := make(chan struct{}, 1)
 	connChan := make(chan *transactableConn, 1)
 
@@ -249,21 +406,25 @@ func (p *Client) release(conn *transactableConn, err error) error {
 				connChan <- conn
 			case <-time.After(timeout):
 				connChan <- nil
-				p.potentialConns <- struct{}{}
-				if e := conn.Close(); e != nil {
+				if e := p.closeConn(conn, closedIdle, nil); e != nil {
 					log.Println("error while closing idle connection:", e)
 				}
 			}
 		}()
 	default:
 		// we have MinConns idle so no need to keep this connection.
-		p.potentialConns <- struct{}{}
-		return conn.Close()
+		return p.closeConn(conn, closedIdle, nil)
 	}
 
 	return nil
 }
 
+// Stats returns statistics about the client's connection pool.
+// Clients returned by the With* methods share their parent's pool.
+func (p *Client) Stats() PoolStats {
+	return p.stats.snapshot()
+}
+
 // EnsureConnected forces the client to connect if it hasn't already.
 func (p *Client) EnsureConnected(ctx context.Context) error {
 	conn, err := p.acquire(ctx)
@@ -277,7 +438,11 @@ func (p *Client) EnsureConnected(ctx context.Context) error {
 // Close closes all connections in the pool.
 // Calling close blocks until all acquired connections have been released,
 // and returns an error if called more than once.
-func (p *Client) Close() error {
+func (p *Client) Close() (err error) {
+	// Stop the background health checks first so that a connection they are
+	// opening doesn't hold isClosedMutex until the connect attempt times out.
+	p.stop()
+
 	p.isClosedMutex.Lock()
 	defer p.isClosedMutex.Unlock()
 
@@ -286,6 +451,10 @@ func (p *Client) Close() error {
 	}
 	*p.isClosed = true
 
+	if p.replicas != nil {
+		defer func() { err = wrapAll(err, p.replicas.Close()) }()
+	}
+
 	p.potentialConnsMutext.Lock()
 	if p.potentialConns == nil {
 		// The client never made any connections.
@@ -303,6 +472,7 @@ func (p *Client) Close() error {
 			go func(i int) {
 				conn := acquireIfNotTimedout()
 				if conn != nil {
+					p.stats.closed(closedClient, nil)
 					errs[i] = conn.Close()
 				}
 				wg.Done()
@@ -315,6 +485,28 @@ func (p *Client) Close() error {
 	return wrapAll(errs...)
 }
 
+// route returns the pool that runs a query. When read only routing is enabled
+// queries that are known to be read only, because their cached capabilities
+// are zero, run on the read replicas. All other queries run on the primary.
+func (p *Client) route(method, cmd string, out interface{}) *Client {
+	if !p.readOnlyRouting || p.replicas == nil {
+		return p
+	}
+
+	q, err := newQuery(method, cmd, nil, 0, nil, out, true, nil, nil,
+		p.compilation)
+	if err != nil {
+		return p
+	}
+
+	capabilities, ok := p.getCachedCapabilities(q)
+	if !ok || capabilities != 0 {
+		return p
+	}
+
+	return p.replicas
+}
+
 // Execute an EdgeQL command (or commands).
 func (p *Client) Execute(
 	ctx context.Context,
@@ -335,6 +527,8 @@ func (p *Client) Execute(
 		nil,
 		true,
 		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
 	)
 	if err != nil {
 		return err
@@ -351,14 +545,65 @@ func (p *Client) Query(
 	out interface{},
 	args ...interface{},
 ) error {
-	conn, err := p.acquire(ctx)
+	pool := p.route("Query", cmd, out)
+	conn, err := pool.acquire(ctx)
 	if err != nil {
 		return err
 	}
 
 	err = runQuery(
-		ctx, conn, "Query", cmd, out, args, p.state, p.warningHandler)
-	return firstError(err, p.release(conn, err))
+		ctx,
+		conn,
+		"Query",
+		cmd,
+		out,
+		args,
+		p.state,
+		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
+	)
+	return firstError(err, pool.release(conn, err))
+}
+
+// QueryIter runs a query and returns an iterator over its results.
+// Unlike Query() the results are decoded as they are received
+// instead of being collected into a slice.
+// The returned Rows holds a connection from the pool until it is closed.
+func (p *Client) QueryIter(
+	ctx context.Context,
+	cmd string,
+	args ...interface{},
+) (*Rows, error) {
+	pool := p.route("QueryIter", cmd, nil)
+	conn, err := pool.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := newQuery(
+		"QueryIter",
+		cmd,
+		args,
+		conn.capabilities1pX(),
+		p.state,
+		nil,
+		true,
+		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
+	)
+	if err != nil {
+		return nil, firstError(err, pool.release(conn, nil))
+	}
+
+	rows, err := conn.queryIter(ctx, q)
+	if err != nil {
+		return nil, firstError(err, pool.release(conn, err))
+	}
+
+	rows.release = func(err error) error { return pool.release(conn, err) }
+	return rows, nil
 }
 
 // QuerySingle runs a singleton-returning query and returns its element.
@@ -371,7 +616,8 @@ func (p *Client) QuerySingle(
 	out interface{},
 	args ...interface{},
 ) error {
-	conn, err := p.acquire(ctx)
+	pool := p.route("QuerySingle", cmd, out)
+	conn, err := pool.acquire(ctx)
 	if err != nil {
 		return err
 	}
@@ -385,8 +631,10 @@ func (p *Client) QuerySingle(
 		args,
 		p.state,
 		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
 	)
-	return firstError(err, p.release(conn, err))
+	return firstError(err, pool.release(conn, err))
 }
 
 // QueryJSON runs a query and return the results as JSON.
@@ -396,7 +644,8 @@ func (p *Client) QueryJSON(
 	out *[]byte,
 	args ...interface{},
 ) error {
-	conn, err := p.acquire(ctx)
+	pool := p.route("QueryJSON", cmd, out)
+	conn, err := pool.acquire(ctx)
 	if err != nil {
 		return err
 	}
@@ -410,8 +659,10 @@ func (p *Client) QueryJSON(
 		args,
 		p.state,
 		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
 	)
-	return firstError(err, p.release(conn, err))
+	return firstError(err, pool.release(conn, err))
 }
 
 // QuerySingleJSON runs a singleton-returning query.
@@ -423,7 +674,8 @@ func (p *Client) QuerySingleJSON(
 	out interface{},
 	args ...interface{},
 ) error {
-	conn, err := p.acquire(ctx)
+	pool := p.route("QuerySingleJSON", cmd, out)
+	conn, err := pool.acquire(ctx)
 	if err != nil {
 		return err
 	}
@@ -437,8 +689,10 @@ func (p *Client) QuerySingleJSON(
 		args,
 		p.state,
 		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
 	)
-	return firstError(err, p.release(conn, err))
+	return firstError(err, pool.release(conn, err))
 }
 
 // QuerySQL runs a SQL query and returns the results.
@@ -448,14 +702,25 @@ func (p *Client) QuerySQL(
 	out interface{},
 	args ...interface{},
 ) error {
-	conn, err := p.acquire(ctx)
+	pool := p.route("QuerySQL", cmd, out)
+	conn, err := pool.acquire(ctx)
 	if err != nil {
 		return err
 	}
 
 	err = runQuery(
-		ctx, conn, "QuerySQL", cmd, out, args, p.state, p.warningHandler)
-	return firstError(err, p.release(conn, err))
+		ctx,
+		conn,
+		"QuerySQL",
+		cmd,
+		out,
+		args,
+		p.state,
+		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
+	)
+	return firstError(err, pool.release(conn, err))
 }
 
 // ExecuteSQL executes a SQL command (or commands).
@@ -478,6 +743,8 @@ func (p *Client) ExecuteSQL(
 		nil,
 		true,
 		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
 	)
 	if err != nil {
 		return err
@@ -507,6 +774,39 @@ func (p *Client) Tx(ctx context.Context, action TxBlock) error {
 		return err
 	}
 
-	err = conn.tx(ctx, action, p.state, p.warningHandler)
+	err = conn.tx(
+		ctx,
+		action,
+		p.state,
+		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
+	)
+	return firstError(err, p.release(conn, err))
+}
+
+// Dump writes a dump of the client's database to w.
+// The dump uses the same file format as the edgedb dump command,
+// it can be restored with Restore() or the edgedb restore command.
+func (p *Client) Dump(ctx context.Context, w io.Writer) error {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = conn.dump(ctx, w)
+	return firstError(err, p.release(conn, err))
+}
+
+// Restore restores the client's database from a dump read from r.
+// The dump must be in the format written by Dump()
+// or the edgedb dump command. The database must be empty.
+func (p *Client) Restore(ctx context.Context, r io.Reader) error {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = conn.restore(ctx, r)
 	return firstError(err, p.release(conn, err))
 }
@@ -19,11 +19,16 @@ package edgedb
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/edgedb/edgedb-go/internal/buff"
 	"github.com/edgedb/edgedb-go/internal/edgedbtypes"
 	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/edgedb/edgedb-go/internal/soc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -249,6 +254,303 @@ func TestQuerySQL(t *testing.T) {
 	}
 }
 
+func TestQueryCancelled(t *testing.T) {
+	ctx := context.Background()
+	p, err := CreateClient(ctx, opts)
+	require.NoError(t, err)
+	defer p.Close() // nolint:errcheck
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	var result int64
+	err = p.QuerySingle(
+		cancelCtx,
+		"SELECT count(range_unpack(range(0, 1_000_000_000)))",
+		&result,
+	)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The client reconnects after the cancelled query.
+	err = p.QuerySingle(ctx, "SELECT 1", &result)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+}
+
+func TestCancelOnDone(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close() // nolint:errcheck
+	conn := &protocolConnection{soc: &autoClosingSocket{conn: client}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := conn.cancelOnDone(ctx)
+	stop()
+	cancel()
+	assert.False(t, conn.isClosed(), "stopped before cancel")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	stop = conn.cancelOnDone(ctx)
+	cancel()
+	require.Eventually(t, conn.isClosed, time.Second, time.Millisecond)
+	stop()
+
+	err := conn.cancelledError(ctx, &clientConnectionClosedError{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPing(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close() // nolint:errcheck
+
+	conn := &protocolConnection{
+		soc:                 &autoClosingSocket{conn: client},
+		acquireReaderSignal: make(chan struct{}, 1),
+		readerChan:          make(chan *buff.Reader, 1),
+	}
+	toBeDeserialized := make(chan *soc.Data, 2)
+	go soc.Read(conn.soc, soc.NewMemPool(4, 256*1024), toBeDeserialized)
+	require.NoError(t, conn.releaseReader(buff.NewReader(toBeDeserialized)))
+
+	go func() {
+		msg := make([]byte, 5)
+		if _, err := io.ReadFull(server, msg); err != nil {
+			return
+		}
+		// ReadyForCommand with no headers and an idle transaction state
+		_, _ = server.Write([]byte{'Z', 0, 0, 0, 7, 0, 0, 'I'})
+	}()
+
+	ctx := context.Background()
+	pingCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NoError(t, conn.ping(pingCtx))
+
+	// The server doesn't answer the second Sync.
+	go func() { _, _ = io.ReadFull(server, make([]byte, 5)) }()
+
+	pingCtx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, conn.ping(pingCtx))
+	assert.True(t, conn.isClosed())
+}
+
+type testMetrics struct {
+	mu       sync.Mutex
+	acquired int
+	released int
+	connects int
+	closed   int
+}
+
+func (m *testMetrics) OnAcquire(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acquired++
+}
+
+func (m *testMetrics) OnRelease() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.released++
+}
+
+func (m *testMetrics) OnConnect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connects++
+}
+
+func (m *testMetrics) OnClose(error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed++
+}
+
+func TestClientStats(t *testing.T) {
+	ctx := context.Background()
+	metrics := &testMetrics{}
+	o := opts
+	o.Metrics = metrics
+	p, err := CreateClient(ctx, o)
+	require.NoError(t, err)
+
+	assert.Equal(t, PoolStats{}, p.Stats())
+	require.NoError(t, p.EnsureConnected(ctx))
+
+	stats := p.Stats()
+	assert.Equal(t, 1, stats.Open)
+	assert.Equal(t, 1, stats.Idle)
+	assert.Equal(t, 0, stats.InUse)
+	assert.Equal(t, uint64(1), stats.Acquired)
+
+	rows, err := p.WithWarningHandler(nil).QueryIter(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	// copied clients share the pool's stats.
+	stats = p.Stats()
+	assert.Equal(t, 1, stats.InUse)
+	assert.Equal(t, uint64(2), stats.Acquired)
+
+	require.NoError(t, rows.Close())
+	assert.Equal(t, 0, p.Stats().InUse)
+
+	require.NoError(t, p.Close())
+	assert.Equal(t, 0, p.Stats().Open)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	assert.Equal(t, 2, metrics.acquired)
+	assert.Equal(t, 2, metrics.released)
+	assert.Equal(t, 1, metrics.connects)
+	assert.Equal(t, 1, metrics.closed)
+}
+
+func TestClientMinIdleConns(t *testing.T) {
+	ctx := context.Background()
+	o := opts
+	o.Concurrency = 4
+	o.MinIdleConns = 2
+	o.HealthCheckInterval = 50 * time.Millisecond
+	p, err := CreateClient(ctx, o)
+	require.NoError(t, err)
+	defer p.Close() // nolint:errcheck
+
+	require.Eventually(t, func() bool {
+		return p.Stats().Idle >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestClientCloseWhileFillingIdleConns(t *testing.T) {
+	// The server accepts connections but never answers.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close() // nolint:errcheck
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close() // nolint:errcheck
+		}
+	}()
+
+	p, err := CreateClient(context.Background(), Options{
+		Host:                "127.0.0.1",
+		Port:                ln.Addr().(*net.TCPAddr).Port,
+		User:                "test",
+		WaitUntilAvailable:  time.Minute,
+		MinIdleConns:        1,
+		HealthCheckInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	// Give the health checks time to start connecting.
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, p.Close())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestClientMaxConnLifetime(t *testing.T) {
+	ctx := context.Background()
+	o := opts
+	o.MaxConnLifetime = 100 * time.Millisecond
+	o.HealthCheckInterval = 50 * time.Millisecond
+	p, err := CreateClient(ctx, o)
+	require.NoError(t, err)
+	defer p.Close() // nolint:errcheck
+
+	require.NoError(t, p.EnsureConnected(ctx))
+	require.Eventually(t, func() bool {
+		return p.Stats().LifetimeClosed > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var result int64
+	require.NoError(t, p.QuerySingle(ctx, "SELECT 1", &result))
+	assert.Equal(t, int64(1), result)
+}
+
+func TestClientShutdown(t *testing.T) {
+	ctx := context.Background()
+	p, err := CreateClient(ctx, opts)
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	txErr := make(chan error)
+	go func() {
+		txErr <- p.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+			close(started)
+			<-finish
+			var result int64
+			return tx.QuerySingle(ctx, "SELECT 1", &result)
+		})
+	}()
+	<-started
+
+	shutdownErr := make(chan error)
+	go func() { shutdownErr <- p.Shutdown(ctx) }()
+
+	// New queries are rejected while the transaction is running.
+	require.Eventually(t, func() bool {
+		return p.Execute(ctx, "SELECT 1") != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	select {
+	case <-shutdownErr:
+		t.Fatal("Shutdown returned before the transaction finished")
+	default:
+	}
+
+	close(finish)
+	require.NoError(t, <-txErr)
+	require.NoError(t, <-shutdownErr)
+	assert.Equal(t, 0, p.Stats().Open)
+
+	err = p.Close()
+	assert.EqualError(t, err, "edgedb.InterfaceError: client closed")
+}
+
+func TestClientShutdownTimeout(t *testing.T) {
+	ctx := context.Background()
+	p, err := CreateClient(ctx, opts)
+	require.NoError(t, err)
+	require.NoError(t, p.EnsureConnected(ctx))
+
+	queryErr := make(chan error)
+	go func() {
+		var result int64
+		queryErr <- p.QuerySingle(
+			ctx,
+			"SELECT count(range_unpack(range(0, 1_000_000_000)))",
+			&result,
+		)
+	}()
+
+	require.Eventually(t, func() bool {
+		return p.Stats().InUse == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = p.Shutdown(timeoutCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The running query is stopped by closing its connection.
+	assert.Error(t, <-queryErr)
+	assert.Equal(t, 0, p.Stats().Open)
+}
+
 // TODO: return when session_idle_timeout changes
 // will be reflected at connection creation
 
@@ -18,7 +18,13 @@ package edgedb
 
 import (
 	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
 	"github.com/stretchr/testify/assert"
@@ -57,6 +63,168 @@ func TestAuth(t *testing.T) {
 	assert.EqualError(t, err, msg)
 }
 
+type rotatingCredentials struct {
+	mu    sync.Mutex
+	calls []bool
+}
+
+func (r *rotatingCredentials) Credentials(
+	_ context.Context,
+	stale bool,
+) (ConnectionCredentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, stale)
+
+	// The first password has already been rotated by the server.
+	password := "expired"
+	if stale {
+		password = "secret"
+	}
+
+	return ConnectionCredentials{
+		User:     "user_with_password",
+		Password: password,
+	}, nil
+}
+
+func TestCredentialsProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := &rotatingCredentials{}
+	p, err := CreateClient(ctx, Options{
+		Host:                opts.Host,
+		Port:                opts.Port,
+		User:                "edgedb",
+		Password:            types.NewOptionalStr("wrong"),
+		Database:            opts.Database,
+		TLSOptions:          opts.TLSOptions,
+		CredentialsProvider: provider,
+	})
+	require.NoError(t, err)
+	defer p.Close() // nolint:errcheck
+
+	var result string
+	err = p.QuerySingle(ctx, "SELECT 'It worked!';", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "It worked!", result)
+	assert.Equal(t, []bool{false, true}, provider.calls)
+}
+
+func TestCredentialsProviderError(t *testing.T) {
+	cfg := &connConfig{
+		user:     "edgedb",
+		password: "secret",
+		credentialsProvider: credentialsFunc(func(
+			context.Context,
+			bool,
+		) (ConnectionCredentials, error) {
+			return ConnectionCredentials{}, errors.New("vault is sealed")
+		}),
+	}
+
+	_, err := cfg.withProvidedCredentials(context.Background(), false)
+	assert.EqualError(t, err, "edgedb.ClientConnectionFailedError: "+
+		"cannot get credentials: vault is sealed")
+}
+
+type credentialsFunc func(context.Context, bool) (
+	ConnectionCredentials, error)
+
+func (f credentialsFunc) Credentials(
+	ctx context.Context,
+	stale bool,
+) (ConnectionCredentials, error) {
+	return f(ctx, stale)
+}
+
+func TestProvidedCredentials(t *testing.T) {
+	var stale []bool
+	cfg := &connConfig{
+		user:      "edgedb",
+		password:  "old",
+		secretKey: "old key",
+		credentialsProvider: credentialsFunc(func(
+			_ context.Context,
+			s bool,
+		) (ConnectionCredentials, error) {
+			stale = append(stale, s)
+			return ConnectionCredentials{Password: "new"}, nil
+		}),
+	}
+
+	provided, err := cfg.withProvidedCredentials(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, stale)
+	assert.Equal(t, "edgedb", provided.user)
+	assert.Equal(t, "new", provided.password)
+	assert.Equal(t, "old key", provided.secretKey)
+
+	// The resolved config is not modified.
+	assert.Equal(t, "old", cfg.password)
+}
+
+func TestCredentialsProviderStaleOnlyOnRetry(t *testing.T) {
+	var stale []bool
+	dialErrs := []error{
+		&authenticationError{msg: "password rejected"},
+		syscall.ECONNREFUSED,
+		errors.New("done"),
+	}
+
+	conn := &reconnectingConn{cfg: &connConfig{
+		addr:               dialArgs{"tcp", "localhost:5656"},
+		waitUntilAvailable: time.Minute,
+		credentialsProvider: credentialsFunc(func(
+			_ context.Context,
+			s bool,
+		) (ConnectionCredentials, error) {
+			stale = append(stale, s)
+			return ConnectionCredentials{Password: "secret"}, nil
+		}),
+		dialer: func(context.Context, string, string) (net.Conn, error) {
+			err := dialErrs[0]
+			dialErrs = dialErrs[1:]
+			return nil, err
+		},
+	}}
+
+	err := conn.reconnect(context.Background(), false)
+	assert.EqualError(t, err, "edgedb.ClientConnectionFailedError: done")
+
+	// Only the retry after the authentication error asks for
+	// fresh credentials.
+	assert.Equal(t, []bool{false, true, false}, stale)
+}
+
+func TestConnectUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".s.EDGEDB.5656")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer listener.Close() // nolint:errcheck
+
+	go func() {
+		conn, e := listener.Accept()
+		if e != nil {
+			return
+		}
+		defer conn.Close() // nolint:errcheck
+		_, _ = conn.Write([]byte("plaintext"))
+	}()
+
+	socket, err := connectAutoClosingSocket(
+		context.Background(),
+		&connConfig{addr: dialArgs{"unix", path}, tlsSecurity: "strict"},
+	)
+	require.NoError(t, err)
+	defer socket.Close() // nolint:errcheck
+
+	// The connection should not attempt a TLS handshake.
+	buf := make([]byte, 9)
+	_, err = socket.conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(buf))
+}
+
 func TestCloudClientHandshakeMessage(t *testing.T) {
 	params := map[string]string{
 		"database":   "mydb",
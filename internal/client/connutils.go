@@ -59,6 +59,9 @@ var (
 
 type connConfig struct {
 	addr               dialArgs
+	fallbackAddrs      []dialArgs
+	hostOrder          HostOrder
+	readReplicas       []dialArgs
 	user               string
 	password           string
 	database           string
@@ -68,10 +71,21 @@ type connConfig struct {
 	tlsCAData          []byte
 	tlsSecurity        string
 	tlsServerName      string
+	tlsClientCert      *tls.Certificate
+	tlsGetClientCert   getClientCertFunc
 	serverSettings     *snc.ServerSettings
 	secretKey          string
+	serverLogHandler   ServerLogHandler
+	dialer             DialFunc
+	proxy              *url.URL
+
+	credentialsProvider CredentialsProvider
 }
 
+// getClientCertFunc is the type of tls.Config.GetClientCertificate.
+type getClientCertFunc = func(*tls.CertificateRequestInfo) (
+	*tls.Certificate, error)
+
 func (c *connConfig) tlsConfig() (*tls.Config, error) {
 	var roots *x509.CertPool
 	if len(c.tlsCAData) != 0 {
@@ -88,9 +102,14 @@ func (c *connConfig) tlsConfig() (*tls.Config, error) {
 	}
 
 	tlsConfig := &tls.Config{
-		RootCAs:    roots,
-		NextProtos: []string{"edgedb-binary"},
-		ServerName: c.tlsServerName,
+		RootCAs:              roots,
+		NextProtos:           []string{"edgedb-binary"},
+		ServerName:           c.tlsServerName,
+		GetClientCertificate: c.tlsGetClientCert,
+	}
+
+	if c.tlsClientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*c.tlsClientCert}
 	}
 
 	switch c.tlsSecurity {
@@ -136,12 +155,16 @@ type configResolver struct {
 	tlsCAData          cfgVal // []byte
 	tlsSecurity        cfgVal // string
 	tlsServerName      cfgVal // string
+	tlsClientCert      cfgVal // []byte or getClientCertFunc
+	tlsClientKey       cfgVal // []byte
 	waitUntilAvailable cfgVal // time.Duration
 	serverSettings     *snc.ServerSettings
 	secretKey          cfgVal // string
 	profile            cfgVal // string
 	instance           cfgVal // string
 	org                cfgVal // string
+	readReplicas       cfgVal // string
+	hostOrder          cfgVal // HostOrder
 }
 
 func (r *configResolver) setInstance(val, source string) error {
@@ -176,17 +199,55 @@ func (r *configResolver) setHost(val, source string) error {
 	if r.host.val != nil {
 		return nil
 	}
-	if strings.Contains(val, "/") {
-		return fmt.Errorf(
-			"invalid host: unix socket paths not supported, got %q", val)
+	if isUnixSocketPath(val) {
+		r.host = cfgVal{val: val, source: source}
+		return nil
+	}
+	if isHostList(val) {
+		if _, err := parseHostList(val, 5656); err != nil {
+			return err
+		}
+		r.host = cfgVal{val: val, source: source}
+		return nil
 	}
-	if val == "" || strings.Contains(val, ",") {
+	if val == "" || strings.Contains(val, "/") {
 		return fmt.Errorf(`invalid host: %q`, val)
 	}
 	r.host = cfgVal{val: val, source: source}
 	return nil
 }
 
+// isUnixSocketPath returns true if host is the path of a unix domain socket
+// instead of a host name.
+func isUnixSocketPath(host string) bool {
+	return strings.HasPrefix(host, "/")
+}
+
+func (r *configResolver) setReadReplicas(val, source string) error {
+	if r.readReplicas.val != nil {
+		return nil
+	}
+	if val != "" {
+		if _, err := parseHostList(val, 5656); err != nil {
+			return fmt.Errorf("invalid read replicas: %w", err)
+		}
+	}
+	r.readReplicas = cfgVal{val: val, source: source}
+	return nil
+}
+
+func (r *configResolver) setHostOrder(val, source string) error {
+	if r.hostOrder.val != nil {
+		return nil
+	}
+	order, err := parseHostOrder(val)
+	if err != nil {
+		return err
+	}
+	r.hostOrder = cfgVal{val: order, source: source}
+	return nil
+}
+
 func (r *configResolver) setPort(val int, source string) error {
 	if r.port.val != nil {
 		return nil
@@ -257,6 +318,44 @@ func (r *configResolver) setTLSCAFile(file, source string) error {
 	return nil
 }
 
+func (r *configResolver) setTLSClientCertData(data []byte, source string) {
+	if r.tlsClientCert.val != nil {
+		return
+	}
+	r.tlsClientCert = cfgVal{val: data, source: source}
+}
+
+func (r *configResolver) setTLSClientCertFile(file, source string) error {
+	if r.tlsClientCert.val != nil {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	r.tlsClientCert = cfgVal{val: data, source: source}
+	return nil
+}
+
+func (r *configResolver) setTLSClientKeyData(data []byte, source string) {
+	if r.tlsClientKey.val != nil {
+		return
+	}
+	r.tlsClientKey = cfgVal{val: data, source: source}
+}
+
+func (r *configResolver) setTLSClientKeyFile(file, source string) error {
+	if r.tlsClientKey.val != nil {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	r.tlsClientKey = cfgVal{val: data, source: source}
+	return nil
+}
+
 func (r *configResolver) setTLSSecurity(val string, source string) error {
 	if r.tlsSecurity.val != nil {
 		return nil
@@ -280,6 +379,64 @@ func (r *configResolver) setTLSServerName(val string, source string) error {
 	return nil
 }
 
+func (r *configResolver) resolveClientCertOptions(opts *TLSOptions) error {
+	var certSources []string
+
+	if opts.ClientCert != nil {
+		certSources = append(certSources, "TLSOptions.ClientCert")
+		r.setTLSClientCertData(opts.ClientCert, "TLSOptions.ClientCert option")
+	}
+
+	if opts.ClientCertFile != "" {
+		certSources = append(certSources, "TLSOptions.ClientCertFile")
+		err := r.setTLSClientCertFile(
+			opts.ClientCertFile, "TLSOptions.ClientCertFile option")
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.GetClientCertificate != nil {
+		certSources = append(certSources, "TLSOptions.GetClientCertificate")
+		if r.tlsClientCert.val == nil {
+			r.tlsClientCert = cfgVal{
+				val:    opts.GetClientCertificate,
+				source: "TLSOptions.GetClientCertificate option",
+			}
+		}
+	}
+
+	if len(certSources) > 1 {
+		return fmt.Errorf(
+			"mutually exclusive options set in Options: %v",
+			englishList(certSources, "and"))
+	}
+
+	var keySources []string
+
+	if opts.ClientKey != nil {
+		keySources = append(keySources, "TLSOptions.ClientKey")
+		r.setTLSClientKeyData(opts.ClientKey, "TLSOptions.ClientKey option")
+	}
+
+	if opts.ClientKeyFile != "" {
+		keySources = append(keySources, "TLSOptions.ClientKeyFile")
+		err := r.setTLSClientKeyFile(
+			opts.ClientKeyFile, "TLSOptions.ClientKeyFile option")
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(keySources) > 1 {
+		return fmt.Errorf(
+			"mutually exclusive options set in Options: %v",
+			englishList(keySources, "and"))
+	}
+
+	return nil
+}
+
 func (r *configResolver) setWaitUntilAvailable(
 	val time.Duration,
 	source string,
@@ -348,6 +505,21 @@ func (r *configResolver) resolveOptions(
 		}
 	}
 
+	if len(opts.ReadReplicas) != 0 {
+		e := r.setReadReplicas(
+			strings.Join(opts.ReadReplicas, ","), "ReadReplicas option")
+		if e != nil {
+			return e
+		}
+	}
+
+	if opts.HostOrder != "" {
+		e := r.setHostOrder(string(opts.HostOrder), "HostOrder option")
+		if e != nil {
+			return e
+		}
+	}
+
 	if opts.Database != "" {
 		if e := r.setDatabase(opts.Database, "Database options"); e != nil {
 			return e
@@ -408,6 +580,10 @@ func (r *configResolver) resolveOptions(
 			englishList(caSources, "and"))
 	}
 
+	if e := r.resolveClientCertOptions(&opts.TLSOptions); e != nil {
+		return e
+	}
+
 	var secSources []string
 
 	if opts.TLSSecurity != "" {
@@ -483,7 +659,8 @@ func (r *configResolver) resolveDSN(
 		return err
 	}
 
-	val, err := popDSNValue(query, uri.Hostname(), "host", r.host.val == nil)
+	host, port := dsnHostPort(uri)
+	val, err := popDSNValue(query, host, "host", r.host.val == nil)
 	if err != nil {
 		return err
 	}
@@ -493,7 +670,7 @@ func (r *configResolver) resolveDSN(
 		}
 	}
 
-	val, err = popDSNValue(query, uri.Port(), "port", r.port.val == nil)
+	val, err = popDSNValue(query, port, "port", r.port.val == nil)
 	if err != nil {
 		return err
 	}
@@ -550,7 +727,6 @@ func (r *configResolver) resolveDSN(
 		return err
 	}
 	if r.password.val == nil && ok {
-		// XXX: what is the source supposed to be here?
 		r.setPassword(pwd, source)
 	} else if r.password.val == nil && val.val != nil {
 		r.setPassword(val.val.(string), source+val.source)
@@ -569,6 +745,39 @@ func (r *configResolver) resolveDSN(
 		}
 	}
 
+	// A client key is only used with a certificate from the same source.
+	certSet := r.tlsClientCert.val != nil
+
+	val, err = popDSNValue(query, "", "tls_client_cert_file",
+		r.tlsClientCert.val == nil)
+	if err != nil {
+		return err
+	}
+	if val.val != nil {
+		if paths.testDir != "" {
+			val.val = filepath.Join(paths.testDir, val.val.(string))
+		}
+		e := r.setTLSClientCertFile(val.val.(string), source+val.source)
+		if e != nil {
+			return e
+		}
+	}
+
+	val, err = popDSNValue(query, "", "tls_client_key_file",
+		r.tlsClientKey.val == nil && !certSet)
+	if err != nil {
+		return err
+	}
+	if val.val != nil {
+		if paths.testDir != "" {
+			val.val = filepath.Join(paths.testDir, val.val.(string))
+		}
+		e := r.setTLSClientKeyFile(val.val.(string), source+val.source)
+		if e != nil {
+			return e
+		}
+	}
+
 	val, err = popDSNValue(query, "", "tls_verify_hostname",
 		r.tlsSecurity.val == nil)
 	if err != nil {
@@ -629,6 +838,29 @@ func (r *configResolver) resolveDSN(
 		}
 	}
 
+	val, err = popDSNValue(
+		query, "", "read_replicas", r.readReplicas.val == nil)
+	if err != nil {
+		return err
+	}
+	if val.val != nil {
+		err = r.setReadReplicas(val.val.(string), source+val.source)
+		if err != nil {
+			return err
+		}
+	}
+
+	val, err = popDSNValue(query, "", "host_order", r.hostOrder.val == nil)
+	if err != nil {
+		return err
+	}
+	if val.val != nil {
+		err = r.setHostOrder(val.val.(string), source+val.source)
+		if err != nil {
+			return err
+		}
+	}
+
 	val, err = popDSNValue(query, "", "secret_key", r.secretKey.val == nil)
 	if err != nil {
 		return err
@@ -789,6 +1021,10 @@ func (r *configResolver) resolveEnvVars(paths *cfgPaths) (bool, error) {
 		}
 	}
 
+	if e := r.resolveClientCertEnvVars(); e != nil {
+		return false, e
+	}
+
 	if val, ok := os.LookupEnv("EDGEDB_TLS_SERVER_NAME"); ok {
 		e := r.setTLSServerName(
 			val,
@@ -906,6 +1142,63 @@ func (r *configResolver) resolveEnvVars(paths *cfgPaths) (bool, error) {
 	return true, nil
 }
 
+func (r *configResolver) resolveClientCertEnvVars() error {
+	// A client key is only used with a certificate from the same source.
+	certSet := r.tlsClientCert.val != nil
+
+	var certSources []string
+
+	if cert, ok := os.LookupEnv("EDGEDB_TLS_CLIENT_CERT"); ok {
+		r.setTLSClientCertData([]byte(cert),
+			"EDGEDB_TLS_CLIENT_CERT environment variable")
+		certSources = append(certSources, "EDGEDB_TLS_CLIENT_CERT")
+	}
+
+	if file, ok := os.LookupEnv("EDGEDB_TLS_CLIENT_CERT_FILE"); ok {
+		certSources = append(certSources, "EDGEDB_TLS_CLIENT_CERT_FILE")
+		e := r.setTLSClientCertFile(file,
+			"EDGEDB_TLS_CLIENT_CERT_FILE environment variable")
+		if e != nil {
+			return e
+		}
+	}
+
+	if len(certSources) > 1 {
+		return fmt.Errorf(
+			"mutually exclusive environment variables set: %v",
+			englishList(certSources, "and"))
+	}
+
+	var keySources []string
+
+	if key, ok := os.LookupEnv("EDGEDB_TLS_CLIENT_KEY"); ok {
+		keySources = append(keySources, "EDGEDB_TLS_CLIENT_KEY")
+		if !certSet {
+			r.setTLSClientKeyData([]byte(key),
+				"EDGEDB_TLS_CLIENT_KEY environment variable")
+		}
+	}
+
+	if file, ok := os.LookupEnv("EDGEDB_TLS_CLIENT_KEY_FILE"); ok {
+		keySources = append(keySources, "EDGEDB_TLS_CLIENT_KEY_FILE")
+		if !certSet {
+			e := r.setTLSClientKeyFile(file,
+				"EDGEDB_TLS_CLIENT_KEY_FILE environment variable")
+			if e != nil {
+				return e
+			}
+		}
+	}
+
+	if len(keySources) > 1 {
+		return fmt.Errorf(
+			"mutually exclusive environment variables set: %v",
+			englishList(keySources, "and"))
+	}
+
+	return nil
+}
+
 func (r *configResolver) resolveTOML(paths *cfgPaths) error {
 	toml, err := findEdgeDBTOML(paths)
 	if err != nil {
@@ -990,6 +1283,11 @@ func (r *configResolver) config(opts *Options) (*connConfig, error) {
 		tlsServerName = r.tlsServerName.val.(string)
 	}
 
+	clientCert, getClientCert, err := r.clientCertificate()
+	if err != nil {
+		return nil, err
+	}
+
 	secretKey := ""
 	if r.secretKey.val != nil {
 		secretKey = r.secretKey.val.(string)
@@ -1032,8 +1330,54 @@ func (r *configResolver) config(opts *Options) (*connConfig, error) {
 		password = r.password.val.(string)
 	}
 
+	addrs := []dialArgs{{"tcp", fmt.Sprintf("%v:%v", host, port)}}
+	switch {
+	case isUnixSocketPath(host):
+		addrs = []dialArgs{{"unix", host}}
+	case isHostList(host):
+		addrs, err = parseHostList(host, port)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var readReplicas []dialArgs
+	if r.readReplicas.val != nil && r.readReplicas.val.(string) != "" {
+		readReplicas, err = parseHostList(r.readReplicas.val.(string), port)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hostOrder HostOrder
+	if r.hostOrder.val != nil {
+		hostOrder = r.hostOrder.val.(HostOrder)
+	}
+
+	// Check the proxy environment variables for every host
+	// so that invalid settings are reported before connecting.
+	var proxy *url.URL
+	for i, addr := range append(addrs[:len(addrs):len(addrs)],
+		readReplicas...) {
+		p, e := addrProxy(opts.Dialer, addr)
+		if e != nil {
+			return nil, e
+		}
+		if i == 0 {
+			proxy = p
+		}
+	}
+
+	var fallbackAddrs []dialArgs
+	if len(addrs) > 1 {
+		fallbackAddrs = addrs[1:]
+	}
+
 	return &connConfig{
-		addr:               dialArgs{"tcp", fmt.Sprintf("%v:%v", host, port)},
+		addr:               addrs[0],
+		fallbackAddrs:      fallbackAddrs,
+		hostOrder:          hostOrder,
+		readReplicas:       readReplicas,
 		user:               user,
 		password:           password,
 		database:           database,
@@ -1044,10 +1388,89 @@ func (r *configResolver) config(opts *Options) (*connConfig, error) {
 		tlsCAData:          certData,
 		tlsSecurity:        tlsSecurity,
 		tlsServerName:      tlsServerName,
+		tlsClientCert:      clientCert,
+		tlsGetClientCert:   getClientCert,
 		secretKey:          secretKey,
+		dialer:             opts.Dialer,
+		proxy:              proxy,
 	}, nil
 }
 
+// addrProxy returns the proxy from the environment for addr. Proxies are only
+// used for TCP connections when there is no custom dialer.
+func addrProxy(dialer DialFunc, addr dialArgs) (*url.URL, error) {
+	if dialer != nil || addr.network != "tcp" {
+		return nil, nil
+	}
+
+	return proxyFromEnvironment(addr.address)
+}
+
+// withAddr returns a copy of cfg that connects to addr.
+func (c *connConfig) withAddr(addr dialArgs) (*connConfig, error) {
+	if addr == c.addr {
+		return c, nil
+	}
+
+	proxy, err := addrProxy(c.dialer, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := *c
+	cfg.addr = addr
+	cfg.proxy = proxy
+	return &cfg, nil
+}
+
+// addrs returns the addresses of all hosts starting with the primary host.
+func (c *connConfig) addrs() []dialArgs {
+	return append([]dialArgs{c.addr}, c.fallbackAddrs...)
+}
+
+// clientCertificate parses the resolved client certificate and key. If no key
+// was set the key is expected to be in the certificate's PEM data. If the
+// certificate came from TLSOptions.GetClientCertificate the callback is
+// returned instead.
+func (r *configResolver) clientCertificate() (
+	*tls.Certificate,
+	getClientCertFunc,
+	error,
+) {
+	if r.tlsClientCert.val == nil {
+		if r.tlsClientKey.val != nil {
+			return nil, nil, fmt.Errorf(
+				"TLS client key from %v is set without a client certificate",
+				r.tlsClientKey.source)
+		}
+		return nil, nil, nil
+	}
+
+	if getCert, ok := r.tlsClientCert.val.(getClientCertFunc); ok {
+		if r.tlsClientKey.val != nil {
+			return nil, nil, fmt.Errorf(
+				"TLS client key from %v can not be used with %v",
+				r.tlsClientKey.source, r.tlsClientCert.source)
+		}
+		return nil, getCert, nil
+	}
+
+	certData := r.tlsClientCert.val.([]byte)
+	keyData := certData
+	if r.tlsClientKey.val != nil {
+		keyData = r.tlsClientKey.val.([]byte)
+	}
+
+	cert, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"invalid TLS client certificate from %v: %w",
+			r.tlsClientCert.source, err)
+	}
+
+	return &cert, nil, nil
+}
+
 func getEnvVarSetting(name, defalt string, values ...string) (string, error) {
 	value, ok := os.LookupEnv(name)
 	if !ok || value == "default" || value == "" {
@@ -1230,11 +1653,12 @@ func parseDSN(dsn string) (*url.URL, map[string]string, error) {
 		vals[k] = v[0]
 	}
 
-	if e := validateQueryArg(vals, "host", uri.Hostname()); e != nil {
+	host, port := dsnHostPort(uri)
+	if e := validateQueryArg(vals, "host", host); e != nil {
 		return nil, nil, e
 	}
 
-	if e := validateQueryArg(vals, "port", uri.Port()); e != nil {
+	if e := validateQueryArg(vals, "port", port); e != nil {
 		return nil, nil, e
 	}
 
@@ -1259,6 +1683,14 @@ func parseDSN(dsn string) (*url.URL, map[string]string, error) {
 		return nil, nil, e
 	}
 
+	if e := validateQueryArg(vals, "tls_client_cert_file", ""); e != nil {
+		return nil, nil, e
+	}
+
+	if e := validateQueryArg(vals, "tls_client_key_file", ""); e != nil {
+		return nil, nil, e
+	}
+
 	if e := validateQueryArg(vals, "tls_verify_hostname", ""); e != nil {
 		return nil, nil, e
 	}
@@ -1267,17 +1699,43 @@ func parseDSN(dsn string) (*url.URL, map[string]string, error) {
 		return nil, nil, e
 	}
 
+	if e := validateQueryArg(vals, "read_replicas", ""); e != nil {
+		return nil, nil, e
+	}
+
+	if e := validateQueryArg(vals, "host_order", ""); e != nil {
+		return nil, nil, e
+	}
+
 	return uri, vals, nil
 }
 
+// dsnHostPort returns the host and port from the DSN's netloc. A netloc with
+// multiple hosts is returned as the host and each host can have its own port.
+func dsnHostPort(uri *url.URL) (string, string) {
+	if isHostList(uri.Host) {
+		return uri.Host, ""
+	}
+
+	return uri.Hostname(), uri.Port()
+}
+
 var dsnKeyLookup = map[string][]string{
-	"host":         {"host", "host_env", "host_file"},
-	"port":         {"port", "port_env", "port_file"},
-	"database":     {"database", "database_env", "database_file"},
-	"branch":       {"branch", "branch_env", "branch_file"},
-	"user":         {"user", "user_env", "user_file"},
-	"password":     {"password", "password_env", "password_file"},
-	"tls_ca_file":  {"tls_ca_file", "tls_ca_file_env"},
+	"host":        {"host", "host_env", "host_file"},
+	"port":        {"port", "port_env", "port_file"},
+	"database":    {"database", "database_env", "database_file"},
+	"branch":      {"branch", "branch_env", "branch_file"},
+	"user":        {"user", "user_env", "user_file"},
+	"password":    {"password", "password_env", "password_file"},
+	"tls_ca_file": {"tls_ca_file", "tls_ca_file_env"},
+	"tls_client_cert_file": {
+		"tls_client_cert_file",
+		"tls_client_cert_file_env",
+	},
+	"tls_client_key_file": {
+		"tls_client_key_file",
+		"tls_client_key_file_env",
+	},
 	"tls_security": {"tls_security", "tls_security_env", "tls_security_file"},
 	"tls_server_name": {
 		"tls_server_name",
@@ -1295,6 +1753,12 @@ var dsnKeyLookup = map[string][]string{
 		"wait_until_available_file",
 	},
 	"secret_key": {"secret_key", "secret_key_env", "secret_key_file"},
+	"read_replicas": {
+		"read_replicas",
+		"read_replicas_env",
+		"read_replicas_file",
+	},
+	"host_order": {"host_order", "host_order_env", "host_order_file"},
 }
 
 func validateQueryArg(query map[string]string, name string, val string) error {
@@ -1326,8 +1790,7 @@ func popDSNValue(
 	resolve bool,
 ) (cfgVal, error) {
 	if val != "" {
-		// XXX: what is the source supposed to be here?
-		return cfgVal{val: val, source: "x_x"}, nil
+		return cfgVal{val: val}, nil
 	}
 
 	var key string
@@ -1346,7 +1809,9 @@ func popDSNValue(
 	}
 
 	switch {
-	case ok && key == "tls_ca_file":
+	case ok && key == "tls_ca_file",
+		ok && key == "tls_client_cert_file",
+		ok && key == "tls_client_key_file":
 		source := fmt.Sprintf(" (%v: %q)", key, val)
 		return cfgVal{val: val, source: source}, nil
 	case ok && strings.HasSuffix(key, "_env"):
@@ -1366,7 +1831,9 @@ func popDSNValue(
 		source := fmt.Sprintf(" (%v: %q)", key, val)
 		return cfgVal{val: string(data), source: source}, nil
 	case ok:
-		source := fmt.Sprintf(" (%v: %q)", key, val)
+		// The value is not part of the source
+		// because it can be a password or secret key.
+		source := fmt.Sprintf(" (%v query parameter)", key)
 		return cfgVal{val: val, source: source}, nil
 	default:
 		return cfgVal{}, nil
@@ -18,16 +18,25 @@ package edgedb
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
 	"net"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -241,18 +250,32 @@ func TestConUtils(t *testing.T) {
 			name: "DSN with multiple hosts",
 			dsn:  "edgedb://user@host1,host2/db",
 			expected: Result{
-				err: &configurationError{},
-				errMessage: `edgedb.ConfigurationError: invalid DSN: ` +
-					`invalid host: "host1,host2"`,
+				cfg: connConfig{
+					addr:               dialArgs{"tcp", "host1:5656"},
+					fallbackAddrs:      []dialArgs{{"tcp", "host2:5656"}},
+					user:               "user",
+					database:           "db",
+					branch:             "db",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
 			},
 		},
 		{
 			name: "DSN with multiple hosts and ports",
 			dsn:  "edgedb://user@host1:1111,host2:2222/db",
 			expected: Result{
-				err: &configurationError{},
-				errMessage: `edgedb.ConfigurationError: invalid DSN: ` +
-					`invalid host: "host1:1111,host2"`,
+				cfg: connConfig{
+					addr:               dialArgs{"tcp", "host1:1111"},
+					fallbackAddrs:      []dialArgs{{"tcp", "host2:2222"}},
+					user:               "user",
+					database:           "db",
+					branch:             "db",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
 			},
 		},
 		{
@@ -263,9 +286,16 @@ func TestConUtils(t *testing.T) {
 			},
 			dsn: "",
 			expected: Result{
-				err: &configurationError{},
-				errMessage: `edgedb.ConfigurationError: ` +
-					`invalid host: "host1:1111,host2:2222"`,
+				cfg: connConfig{
+					addr:               dialArgs{"tcp", "host1:1111"},
+					fallbackAddrs:      []dialArgs{{"tcp", "host2:2222"}},
+					user:               "foo",
+					database:           "edgedb",
+					branch:             "__default__",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
 			},
 		},
 		{
@@ -274,10 +304,109 @@ func TestConUtils(t *testing.T) {
 				"EDGEDB_USER": "foo",
 			},
 			dsn: "edgedb:///db?host=host1:1111,host2:2222",
+			expected: Result{
+				cfg: connConfig{
+					addr:               dialArgs{"tcp", "host1:1111"},
+					fallbackAddrs:      []dialArgs{{"tcp", "host2:2222"}},
+					user:               "edgedb",
+					database:           "db",
+					branch:             "db",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "multiple hosts with a default port",
+			opts: Options{
+				Host:      "host1, host2:2222",
+				Port:      1111,
+				HostOrder: HostOrderRandom,
+			},
+			expected: Result{
+				cfg: connConfig{
+					addr:               dialArgs{"tcp", "host1:1111"},
+					fallbackAddrs:      []dialArgs{{"tcp", "host2:2222"}},
+					hostOrder:          HostOrderRandom,
+					user:               "edgedb",
+					database:           "edgedb",
+					branch:             "__default__",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "multiple hosts with an empty host",
+			opts: Options{Host: "host1,,host2"},
+			expected: Result{
+				err: &configurationError{},
+				errMessage: `edgedb.ConfigurationError: ` +
+					`invalid edgedb.Options: invalid host: ""`,
+			},
+		},
+		{
+			name: "multiple hosts with an invalid port",
+			dsn:  "edgedb://host1:1111,host2:99999",
 			expected: Result{
 				err: &configurationError{},
 				errMessage: `edgedb.ConfigurationError: invalid DSN: ` +
-					`invalid host: "host1:1111,host2:2222"`,
+					`invalid port "99999" in host "host2:99999"`,
+			},
+		},
+		{
+			name: "DSN with read replicas",
+			dsn: "edgedb://user@primary/db" +
+				"?read_replicas=replica1,replica2:2222&host_order=random",
+			expected: Result{
+				cfg: connConfig{
+					addr:      dialArgs{"tcp", "primary:5656"},
+					hostOrder: HostOrderRandom,
+					readReplicas: []dialArgs{
+						{"tcp", "replica1:5656"},
+						{"tcp", "replica2:2222"},
+					},
+					user:               "user",
+					database:           "db",
+					branch:             "db",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "read replicas option",
+			opts: Options{
+				Host:         "primary",
+				ReadReplicas: []string{"replica1", "/run/replica.sock"},
+			},
+			expected: Result{
+				cfg: connConfig{
+					addr: dialArgs{"tcp", "primary:5656"},
+					readReplicas: []dialArgs{
+						{"tcp", "replica1:5656"},
+						{"unix", "/run/replica.sock"},
+					},
+					user:               "edgedb",
+					database:           "edgedb",
+					branch:             "__default__",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "invalid host order",
+			dsn:  "edgedb://primary?host_order=fastest",
+			expected: Result{
+				err: &configurationError{},
+				errMessage: `edgedb.ConfigurationError: invalid DSN: ` +
+					`invalid host order "fastest", ` +
+					`expected in_order or random`,
 			},
 		},
 		{
@@ -349,11 +478,55 @@ func TestConUtils(t *testing.T) {
 		{
 			name: "DSN with unix socket",
 			dsn:  "edgedb:///dbname?host=/unix_sock/test&user=spam",
+			expected: Result{
+				cfg: connConfig{
+					addr:               dialArgs{"unix", "/unix_sock/test"},
+					user:               "spam",
+					database:           "dbname",
+					branch:             "dbname",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "unix socket host option",
+			opts: Options{Host: "/run/edgedb.sock", Port: 5656},
+			expected: Result{
+				cfg: connConfig{
+					addr:               dialArgs{"unix", "/run/edgedb.sock"},
+					user:               "edgedb",
+					database:           "edgedb",
+					branch:             "__default__",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "unix socket environment variable",
+			env:  map[string]string{"EDGEDB_HOST": "/tmp/edgedb.sock"},
+			expected: Result{
+				cfg: connConfig{
+					addr:               dialArgs{"unix", "/tmp/edgedb.sock"},
+					user:               "edgedb",
+					database:           "edgedb",
+					branch:             "__default__",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
+			},
+		},
+		{
+			name: "relative host path",
+			opts: Options{Host: "unix_sock/test"},
 			expected: Result{
 				err: &configurationError{},
-				errMessage: `edgedb.ConfigurationError: invalid DSN: ` +
-					`invalid host: unix socket paths not supported, ` +
-					`got "/unix_sock/test"`,
+				errMessage: `edgedb.ConfigurationError: ` +
+					`invalid edgedb.Options: invalid host: "unix_sock/test"`,
 			},
 		},
 		{
@@ -367,12 +540,17 @@ func TestConUtils(t *testing.T) {
 		},
 		{
 			name: "DSN query parameter with unix socket",
-			dsn:  "edgedb://user@?port=56226&host=%2Ftmp",
+			dsn:  "edgedb://user@?port=56226&host=%2Ftmp%2Fedgedb.sock",
 			expected: Result{
-				err: &configurationError{},
-				errMessage: `edgedb.ConfigurationError: invalid DSN: ` +
-					`invalid host: unix socket paths not supported, ` +
-					`got "/tmp"`,
+				cfg: connConfig{
+					addr:               dialArgs{"unix", "/tmp/edgedb.sock"},
+					user:               "user",
+					database:           "edgedb",
+					branch:             "__default__",
+					waitUntilAvailable: 30 * time.Second,
+					tlsSecurity:        "strict",
+					serverSettings:     snc.NewServerSettings(),
+				},
 			},
 		},
 	}
@@ -550,6 +728,33 @@ func createFile(tmpDir, file, data string) error {
 	return os.WriteFile(file, []byte(data), 0644)
 }
 
+// specHostList returns the list of hosts that a shared testcase sets in
+// options, the environment or the DSN, or "" if it doesn't set one.
+func specHostList(opts *Options, env map[string]string, dsn string) string {
+	if isHostList(opts.Host) {
+		return opts.Host
+	}
+
+	if isHostList(env["EDGEDB_HOST"]) {
+		return env["EDGEDB_HOST"]
+	}
+
+	uri, query, err := parseDSN(dsn)
+	if err != nil {
+		return ""
+	}
+
+	if host, _ := dsnHostPort(uri); isHostList(host) {
+		return host
+	}
+
+	if isHostList(query["host"]) {
+		return query["host"]
+	}
+
+	return ""
+}
+
 func TestConnectionParameterResolution(t *testing.T) {
 	data, err := os.ReadFile(
 		"../../shared-client-testcases/connection_testcases.json",
@@ -693,6 +898,37 @@ func TestConnectionParameterResolution(t *testing.T) {
 
 			config, err := parseConnectDSNAndArgs(dsn, &options, paths)
 
+			// The spec expects errors for unix socket paths and for lists
+			// of hosts, both of which this client supports.
+			var specErr string
+			if e, ok := testcase["error"].(map[string]interface{}); ok {
+				specErr, _ = e["type"].(string)
+			}
+
+			switch {
+			case specErr == "unix_socket_unsupported":
+				require.NoError(t, err)
+				assert.Equal(t, "unix", config.addr.network)
+				assert.True(t, isUnixSocketPath(config.addr.address),
+					"%q is not an absolute path", config.addr.address)
+				return
+			case specErr == "invalid_host" && err == nil:
+				hosts := specHostList(&options, env, dsn)
+				require.NotEmpty(t, hosts, "expected an invalid host error")
+
+				entries := strings.Split(hosts, ",")
+				addrs := config.addrs()
+				require.Len(t, addrs, len(entries))
+				for i, entry := range entries {
+					host := strings.TrimSpace(entry)
+					if h, _, e := net.SplitHostPort(host); e == nil {
+						host = h
+					}
+					assert.Contains(t, addrs[i].address, host)
+				}
+				return
+			}
+
 			if testcase["error"] != nil {
 				errType := &configurationError{}
 				require.IsType(t, errType, err)
@@ -818,8 +1054,248 @@ func TestConnectRefusedUnixSocket(t *testing.T) {
 	require.True(t, errors.As(err, &edbErr), "wrong error: %v", err)
 	assert.True(
 		t,
-		edbErr.Category(ConfigurationError),
+		edbErr.Category(ClientConnectionError),
 		"wrong error: %v",
 		err,
 	)
+	assert.True(t, errors.Is(err, syscall.ENOENT), "wrong error: %v", err)
+}
+
+// newTestClientCert returns a PEM encoded self signed certificate and key.
+func newTestClientCert(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "edgedb-go test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(
+		rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: keyDER,
+	})
+	return certPEM, keyPEM
+}
+
+func TestClientCertificate(t *testing.T) {
+	certPEM, keyPEM := newTestClientCert(t)
+	expected, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	bundleFile := filepath.Join(dir, "client.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+	require.NoError(t, os.WriteFile(
+		bundleFile, append(append([]byte{}, certPEM...), keyPEM...), 0600))
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		dsn  string
+		opts Options
+		err  string
+	}{
+		{
+			name: "options data",
+			opts: Options{
+				Host: "localhost",
+				TLSOptions: TLSOptions{
+					ClientCert: certPEM,
+					ClientKey:  keyPEM,
+				},
+			},
+		},
+		{
+			name: "options files",
+			opts: Options{
+				Host: "localhost",
+				TLSOptions: TLSOptions{
+					ClientCertFile: certFile,
+					ClientKeyFile:  keyFile,
+				},
+			},
+		},
+		{
+			name: "options certificate file containing the key",
+			opts: Options{
+				Host:       "localhost",
+				TLSOptions: TLSOptions{ClientCertFile: bundleFile},
+			},
+		},
+		{
+			name: "DSN query parameters",
+			dsn: "edgedb://localhost?tls_client_cert_file=" + certFile +
+				"&tls_client_key_file=" + keyFile,
+		},
+		{
+			name: "environment variables",
+			env: map[string]string{
+				"EDGEDB_HOST":                 "localhost",
+				"EDGEDB_TLS_CLIENT_CERT_FILE": certFile,
+				"EDGEDB_TLS_CLIENT_KEY":       string(keyPEM),
+			},
+		},
+		{
+			name: "keys from lower levels are ignored",
+			env: map[string]string{
+				"EDGEDB_TLS_CLIENT_KEY": "not a key",
+			},
+			opts: Options{
+				Host:       "localhost",
+				TLSOptions: TLSOptions{ClientCertFile: bundleFile},
+			},
+		},
+		{
+			name: "DSN key is ignored with an options certificate",
+			dsn:  "edgedb://localhost?tls_client_key_file=/does/not/exist",
+			opts: Options{
+				TLSOptions: TLSOptions{ClientCert: certPEM, ClientKey: keyPEM},
+			},
+		},
+		{
+			name: "mutually exclusive options",
+			opts: Options{
+				Host: "localhost",
+				TLSOptions: TLSOptions{
+					ClientCert:     certPEM,
+					ClientCertFile: certFile,
+				},
+			},
+			err: "edgedb.ConfigurationError: invalid edgedb.Options: " +
+				"mutually exclusive options set in Options: " +
+				"TLSOptions.ClientCert and TLSOptions.ClientCertFile",
+		},
+		{
+			name: "mutually exclusive environment variables",
+			env: map[string]string{
+				"EDGEDB_HOST":                "localhost",
+				"EDGEDB_TLS_CLIENT_KEY":      string(keyPEM),
+				"EDGEDB_TLS_CLIENT_KEY_FILE": keyFile,
+			},
+			err: "edgedb.ConfigurationError: " +
+				"mutually exclusive environment variables set: " +
+				"EDGEDB_TLS_CLIENT_KEY and EDGEDB_TLS_CLIENT_KEY_FILE",
+		},
+		{
+			name: "key without certificate",
+			opts: Options{
+				Host:       "localhost",
+				TLSOptions: TLSOptions{ClientKey: keyPEM},
+			},
+			err: "edgedb.ConfigurationError: TLS client key from " +
+				"TLSOptions.ClientKey option is set " +
+				"without a client certificate",
+		},
+		{
+			name: "certificate without key",
+			opts: Options{
+				Host:       "localhost",
+				TLSOptions: TLSOptions{ClientCert: certPEM},
+			},
+			err: "edgedb.ConfigurationError: invalid TLS client " +
+				"certificate from TLSOptions.ClientCert option: " +
+				"tls: found a certificate rather than a key " +
+				"in the PEM for the private key",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			cleanup := setenvmap(c.env)
+			defer cleanup()
+
+			config, err := parseConnectDSNAndArgs(
+				c.dsn, &c.opts, newCfgPaths())
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, config.tlsClientCert)
+			assert.Equal(t,
+				expected.Certificate, config.tlsClientCert.Certificate)
+
+			tlsConfig, err := config.tlsConfig()
+			require.NoError(t, err)
+			require.Len(t, tlsConfig.Certificates, 1)
+			assert.Equal(t,
+				expected.Certificate, tlsConfig.Certificates[0].Certificate)
+		})
+	}
+}
+
+func TestGetClientCertificate(t *testing.T) {
+	certPEM, keyPEM := newTestClientCert(t)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	getCert := func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return &cert, nil
+	}
+
+	config, err := parseConnectDSNAndArgs("", &Options{
+		Host:       "localhost",
+		TLSOptions: TLSOptions{GetClientCertificate: getCert},
+	}, newCfgPaths())
+	require.NoError(t, err)
+	assert.Nil(t, config.tlsClientCert)
+
+	tlsConfig, err := config.tlsConfig()
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.GetClientCertificate)
+	got, err := tlsConfig.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, &cert, got)
+
+	_, err = parseConnectDSNAndArgs("", &Options{
+		Host: "localhost",
+		TLSOptions: TLSOptions{
+			ClientCert:           certPEM,
+			GetClientCertificate: getCert,
+		},
+	}, newCfgPaths())
+	assert.EqualError(t, err, "edgedb.ConfigurationError: "+
+		"invalid edgedb.Options: mutually exclusive options set in "+
+		"Options: TLSOptions.ClientCert and TLSOptions.GetClientCertificate")
+
+	// GetClientCertificate takes precedence over
+	// certificates from environment variables.
+	cleanup := setenvmap(map[string]string{
+		"EDGEDB_HOST":            "localhost",
+		"EDGEDB_TLS_CLIENT_CERT": string(certPEM),
+		"EDGEDB_TLS_CLIENT_KEY":  string(keyPEM),
+	})
+	defer cleanup()
+	config, err = parseConnectDSNAndArgs("", &Options{
+		TLSOptions: TLSOptions{GetClientCertificate: getCert},
+	}, newCfgPaths())
+	require.NoError(t, err)
+	assert.Nil(t, config.tlsClientCert)
+	assert.NotNil(t, config.tlsGetClientCert)
+
+	_, err = parseConnectDSNAndArgs("", &Options{
+		TLSOptions: TLSOptions{
+			ClientKey:            keyPEM,
+			GetClientCertificate: getCert,
+		},
+	}, newCfgPaths())
+	assert.EqualError(t, err, "edgedb.ConfigurationError: "+
+		"TLS client key from TLSOptions.ClientKey option can not be used "+
+		"with TLSOptions.GetClientCertificate option")
 }
@@ -0,0 +1,376 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/edgedb/edgedb-go/internal/codecs"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+//go:generate go run golang.org/x/tools/cmd/stringer@v0.25.0 -type TypeKind
+
+// TypeKind is the kind of type described by a TypeDescriptor.
+type TypeKind uint8
+
+const (
+	// KindSet is a set of values. Fields has one element, the set's element
+	// type.
+	KindSet TypeKind = iota + 1
+
+	// KindObject is an object or an input shape. Fields are the object's
+	// properties and links.
+	KindObject
+
+	// KindScalar is a scalar type.
+	KindScalar
+
+	// KindEnum is an enum type.
+	KindEnum
+
+	// KindTuple is an unnamed tuple. Fields are the tuple's elements.
+	KindTuple
+
+	// KindNamedTuple is a named tuple. Fields are the tuple's elements.
+	KindNamedTuple
+
+	// KindArray is an array. Fields has one element, the array's element
+	// type.
+	KindArray
+
+	// KindRange is a range. Fields has one element, the range's element
+	// type.
+	KindRange
+
+	// KindMultiRange is a multirange. Fields has one element, the type of
+	// the multirange's ranges.
+	KindMultiRange
+
+	// KindRecord is an SQL query result row. Fields are the row's columns.
+	KindRecord
+)
+
+// TypeDescriptor describes a query's argument or result type.
+type TypeDescriptor struct {
+	Kind TypeKind
+	ID   types.UUID
+
+	// Name is the type's schema name, for example std::str or default::Mood.
+	// Anonymous types like tuples and object shapes don't have a name.
+	// Servers before EdgeDB 5.0 only send names for standard scalar types.
+	Name string
+
+	// BaseScalar is the name of the standard scalar type that a scalar type
+	// extends. It is the same as Name for standard scalar types.
+	BaseScalar string
+
+	// EnumMembers are the members of an enum type.
+	EnumMembers []string
+
+	// Fields are the type's elements.
+	// See the TypeKind constants for the meaning of Fields for each kind.
+	Fields []DescriptorField
+}
+
+// DescriptorField is an element of a TypeDescriptor.
+type DescriptorField struct {
+	// Name is the field's name. Unnamed tuple elements are named
+	// by their position and set, array and range elements have no name.
+	Name string
+
+	// Required is true if the field always has a value.
+	Required bool
+
+	Type TypeDescriptor
+}
+
+// QueryDescription describes a query's arguments and results.
+type QueryDescription struct {
+	// Input describes the query arguments.
+	Input TypeDescriptor
+
+	// Output describes the query result.
+	Output TypeDescriptor
+
+	// Cardinality is the query result's cardinality.
+	Cardinality Cardinality
+}
+
+// Describe returns a description of cmd's arguments and results without
+// running it.
+func (p *Client) Describe(
+	ctx context.Context,
+	cmd string,
+) (*QueryDescription, error) {
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &query{
+		method:           "Query",
+		lang:             EdgeQL,
+		cmd:              cmd,
+		fmt:              Binary,
+		expCard:          Many,
+		capabilities:     conn.capabilities1pX(),
+		state:            copyState(p.state),
+		parse:            true,
+		warningHandler:   p.warningHandler,
+		serverLogHandler: p.serverLogHandler,
+		compilation:      p.compilation,
+	}
+
+	desc, err := conn.describeQuery(ctx, q)
+	return desc, firstError(err, p.release(conn, err))
+}
+
+func (c *borrowableConn) describeQuery(
+	ctx context.Context,
+	q *query,
+) (*QueryDescription, error) {
+	if e := c.assertUnborrowed(); e != nil {
+		return nil, e
+	}
+
+	return c.conn.describeQuery(ctx, q)
+}
+
+func (c *reconnectingConn) describeQuery(
+	ctx context.Context,
+	q *query,
+) (*QueryDescription, error) {
+	if e := c.ensureConnection(ctx); e != nil {
+		return nil, e
+	}
+
+	return c.borrowableConn.describeQuery(ctx, q)
+}
+
+func (c *protocolConnection) describeQuery(
+	ctx context.Context,
+	q *query,
+) (*QueryDescription, error) {
+	if c.protocolVersion.LT(protocolVersion1p0) {
+		return nil, &unsupportedFeatureError{
+			msg: "the server does not support describing queries, " +
+				"upgrade to 2.0 or newer",
+		}
+	}
+
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	var desc *QueryDescription
+	restore := c.useServerLogHandler(q.serverLogHandler)
+	stop := c.cancelOnDone(ctx)
+	if c.protocolVersion.GTE(protocolVersion2p0) {
+		var d *CommandDescriptionV2
+		d, err = c.parse2pX(r, q)
+		if err == nil {
+			desc, err = describeV2(d)
+		}
+	} else {
+		var d *CommandDescription
+		d, err = c.parse1pX(r, q)
+		if err == nil {
+			desc, err = describeV1(d)
+		}
+	}
+	stop()
+	restore()
+
+	err = c.cancelledError(ctx, err)
+	return desc, firstError(err, c.releaseReader(r))
+}
+
+// baseScalarNames are the names of the standard scalar types
+// for protocol versions that don't send type names.
+var baseScalarNames = map[types.UUID]string{
+	codecs.UUIDID:             "std::uuid",
+	codecs.StrID:              "std::str",
+	codecs.BytesID:            "std::bytes",
+	codecs.Int16ID:            "std::int16",
+	codecs.Int32ID:            "std::int32",
+	codecs.Int64ID:            "std::int64",
+	codecs.Float32ID:          "std::float32",
+	codecs.Float64ID:          "std::float64",
+	codecs.DecimalID:          "std::decimal",
+	codecs.BoolID:             "std::bool",
+	codecs.DateTimeID:         "std::datetime",
+	codecs.LocalDTID:          "cal::local_datetime",
+	codecs.LocalDateID:        "cal::local_date",
+	codecs.LocalTimeID:        "cal::local_time",
+	codecs.DurationID:         "std::duration",
+	codecs.JSONID:             "std::json",
+	codecs.BigIntID:           "std::bigint",
+	codecs.RelativeDurationID: "cal::relative_duration",
+	codecs.DateDurationID:     "cal::date_duration",
+	codecs.MemoryID:           "cfg::memory",
+}
+
+func describeV1(d *CommandDescription) (*QueryDescription, error) {
+	in, err := typeDescriptorV1(d.In)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := typeDescriptorV1(d.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryDescription{Input: in, Output: out, Cardinality: d.Card}, nil
+}
+
+func typeDescriptorV1(desc descriptor.Descriptor) (TypeDescriptor, error) {
+	t := TypeDescriptor{ID: desc.ID}
+
+	switch desc.Type {
+	case descriptor.Set:
+		t.Kind = KindSet
+	case descriptor.Object, descriptor.InputShape:
+		t.Kind = KindObject
+	case descriptor.BaseScalar:
+		t.Kind = KindScalar
+		t.Name = baseScalarNames[desc.ID]
+		t.BaseScalar = t.Name
+	case descriptor.Scalar:
+		base := codecs.GetScalarDescriptor(desc)
+		t.Kind = KindScalar
+		t.BaseScalar = baseScalarNames[base.ID]
+		return t, nil
+	case descriptor.Enum:
+		t.Kind = KindEnum
+		t.EnumMembers = desc.EnumMembers
+	case descriptor.Tuple:
+		t.Kind = KindTuple
+	case descriptor.NamedTuple:
+		t.Kind = KindNamedTuple
+	case descriptor.Array:
+		t.Kind = KindArray
+	case descriptor.Range:
+		t.Kind = KindRange
+	default:
+		return TypeDescriptor{}, &unsupportedFeatureError{msg: fmt.Sprintf(
+			"describing descriptor type 0x%x is not supported", desc.Type)}
+	}
+
+	if len(desc.Fields) > 0 {
+		t.Fields = make([]DescriptorField, len(desc.Fields))
+	}
+
+	for i, field := range desc.Fields {
+		typ, err := typeDescriptorV1(field.Desc)
+		if err != nil {
+			return TypeDescriptor{}, err
+		}
+
+		t.Fields[i] = DescriptorField{
+			Name:     field.Name,
+			Required: field.Required,
+			Type:     typ,
+		}
+	}
+
+	return t, nil
+}
+
+func describeV2(d *CommandDescriptionV2) (*QueryDescription, error) {
+	in, err := typeDescriptorV2(&d.In)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := typeDescriptorV2(&d.Out)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryDescription{Input: in, Output: out, Cardinality: d.Card}, nil
+}
+
+func typeDescriptorV2(desc *descriptor.V2) (TypeDescriptor, error) {
+	t := TypeDescriptor{ID: desc.ID, Name: desc.Name}
+
+	switch desc.Type {
+	case descriptor.Set:
+		t.Kind = KindSet
+	case descriptor.Object, descriptor.InputShape:
+		t.Kind = KindObject
+	case descriptor.BaseScalar, descriptor.Scalar:
+		t.Kind = KindScalar
+		t.BaseScalar = t.Name
+		if len(desc.Ancestors) > 0 {
+			t.BaseScalar = desc.Ancestors[len(desc.Ancestors)-1].Desc.Name
+		}
+	case descriptor.Enum:
+		t.Kind = KindEnum
+		t.EnumMembers = desc.EnumMembers
+	case descriptor.Tuple, descriptor.NamedTuple:
+		// Named tuples are described as tuples with named elements.
+		t.Kind = KindTuple
+		for i, field := range desc.Fields {
+			if field.Name != strconv.Itoa(i) {
+				t.Kind = KindNamedTuple
+				break
+			}
+		}
+	case descriptor.Array:
+		t.Kind = KindArray
+	case descriptor.Range:
+		t.Kind = KindRange
+	case descriptor.MultiRange:
+		t.Kind = KindMultiRange
+	case descriptor.SQLRecord:
+		t.Kind = KindRecord
+	default:
+		return TypeDescriptor{}, &unsupportedFeatureError{msg: fmt.Sprintf(
+			"describing descriptor type 0x%x is not supported", desc.Type)}
+	}
+
+	if len(desc.Fields) > 0 {
+		t.Fields = make([]DescriptorField, len(desc.Fields))
+	}
+
+	for i, field := range desc.Fields {
+		typ, err := typeDescriptorV2(&field.Desc)
+		if err != nil {
+			return TypeDescriptor{}, err
+		}
+
+		t.Fields[i] = DescriptorField{
+			Name:     field.Name,
+			Required: field.Required,
+			Type:     typ,
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,72 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	ctx := context.Background()
+
+	desc, err := client.Describe(
+		ctx,
+		"SELECT (a := <int64>$0, b := <str>$1)",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, One, desc.Cardinality)
+
+	assert.Equal(t, KindObject, desc.Input.Kind)
+	require.Len(t, desc.Input.Fields, 2)
+	assert.Equal(t, "0", desc.Input.Fields[0].Name)
+	assert.True(t, desc.Input.Fields[0].Required)
+	assert.Equal(t, KindScalar, desc.Input.Fields[0].Type.Kind)
+	assert.Equal(t, "std::int64", desc.Input.Fields[0].Type.Name)
+	assert.Equal(t, "1", desc.Input.Fields[1].Name)
+	assert.Equal(t, "std::str", desc.Input.Fields[1].Type.Name)
+
+	assert.Equal(t, KindNamedTuple, desc.Output.Kind)
+	require.Len(t, desc.Output.Fields, 2)
+	assert.Equal(t, "a", desc.Output.Fields[0].Name)
+	assert.Equal(t, "std::int64", desc.Output.Fields[0].Type.Name)
+	assert.Equal(t, "b", desc.Output.Fields[1].Name)
+	assert.Equal(t, "std::str", desc.Output.Fields[1].Type.Name)
+}
+
+func TestDescribeCardinality(t *testing.T) {
+	ctx := context.Background()
+
+	desc, err := client.Describe(ctx, "SELECT {<array<str>>[], ['a']}")
+	require.NoError(t, err)
+	assert.Equal(t, AtLeastOne, desc.Cardinality)
+	assert.Equal(t, KindArray, desc.Output.Kind)
+	require.Len(t, desc.Output.Fields, 1)
+	assert.Equal(t, "std::str", desc.Output.Fields[0].Type.Name)
+}
+
+func TestDescribeError(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := client.Describe(ctx, "SELECT 1 +")
+	var edbErr Error
+	require.ErrorAs(t, err, &edbErr)
+	assert.True(t, edbErr.Category(EdgeQLSyntaxError))
+}
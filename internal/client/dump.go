@@ -0,0 +1,367 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+)
+
+// dumpMagic is written at the start of every dump file.
+// It is followed by the dump format version as a big endian int64.
+var dumpMagic = []byte("\xff\xd8\x00\x00\xd8EDGEDB\x00DUMP\x00")
+
+const dumpFormatVersion = 1
+
+// Dump file packet types. Each packet in a dump file is
+// the packet type, the sha1 sum of the packet data, the data length
+// as a big endian uint32 and the data.
+const (
+	dumpPacketHeader = 'H'
+	dumpPacketBlock  = 'D'
+)
+
+func (c *protocolConnection) dump(ctx context.Context, out io.Writer) error {
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	stop := c.cancelOnDone(ctx)
+	err = c.execDump(r, out)
+	stop()
+
+	err = c.cancelledError(ctx, err)
+	return firstError(err, c.releaseReader(r))
+}
+
+func (c *protocolConnection) execDump(r *buff.Reader, out io.Writer) error {
+	w := buff.NewWriter(c.writeMemory[:0])
+	w.BeginMessage(uint8(Dump))
+	if c.protocolVersion.GTE(protocolVersion3p0) {
+		w.PushUint16(0) // no annotations
+		w.PushUint64(0) // no flags
+	} else {
+		w.PushUint16(0) // no headers
+	}
+	w.EndMessage()
+
+	w.BeginMessage(uint8(Sync))
+	w.EndMessage()
+
+	if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+		return &clientConnectionClosedError{err: e}
+	}
+
+	var (
+		err      error
+		writeErr error
+	)
+	done := buff.NewSignal()
+
+	for r.Next(done.Chan) {
+		switch Message(r.MsgType) {
+		case DumpHeader:
+			if writeErr == nil {
+				writeErr = writeDumpFileHeader(out)
+			}
+			if writeErr == nil {
+				writeErr = writeDumpPacket(out, dumpPacketHeader, r.Buf)
+			}
+			r.DiscardMessage()
+		case DumpBlock:
+			if writeErr == nil {
+				writeErr = writeDumpPacket(out, dumpPacketBlock, r.Buf)
+			}
+			r.DiscardMessage()
+		case CommandComplete:
+			r.DiscardMessage()
+		case ReadyForCommand:
+			decodeReadyForCommandMsg(r)
+			done.Signal()
+		case ErrorResponse:
+			err = wrapAll(err, decodeErrorResponseMsg(r, ""))
+		default:
+			if e := c.fallThrough(r); e != nil {
+				// the connection will not be usable after this x_x
+				return e
+			}
+		}
+	}
+
+	return wrapAll(err, r.Err, writeErr)
+}
+
+func (c *protocolConnection) restore(ctx context.Context, in io.Reader) error {
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	stop := c.cancelOnDone(ctx)
+	err = c.execRestore(r, in)
+	stop()
+
+	err = c.cancelledError(ctx, err)
+	return firstError(err, c.releaseReader(r))
+}
+
+func (c *protocolConnection) execRestore(r *buff.Reader, in io.Reader) error {
+	err := readDumpFileHeader(in)
+	if err != nil {
+		return err
+	}
+
+	typ, header, err := readDumpPacket(in)
+	if errors.Is(err, io.EOF) {
+		return &invalidArgumentError{msg: "the dump file is empty"}
+	} else if err != nil {
+		return err
+	} else if typ != dumpPacketHeader {
+		return &invalidArgumentError{msg: fmt.Sprintf(
+			"the dump file is invalid: expected header packet, got %q",
+			typ)}
+	}
+
+	w := buff.NewWriter(c.writeMemory[:0])
+	w.BeginMessage(uint8(Restore))
+	w.PushUint16(0) // no headers
+	w.PushUint16(1) // jobs
+	w.PushBytes(header)
+	w.EndMessage()
+
+	if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+		return &clientConnectionClosedError{err: e}
+	}
+
+	ready, err := c.waitForRestoreReady(r)
+	if !ready {
+		return err
+	}
+
+	for {
+		typ, block, e := readDumpPacket(in)
+		if errors.Is(e, io.EOF) {
+			break
+		} else if e == nil && typ != dumpPacketBlock {
+			e = &invalidArgumentError{msg: fmt.Sprintf(
+				"the dump file is invalid: expected data packet, got %q",
+				typ)}
+		}
+
+		if e != nil {
+			// The server is waiting for more data blocks and there is no way
+			// to cancel the restore, so the connection can not be reused.
+			return wrapAll(e, c.soc.Close())
+		}
+
+		w = buff.NewWriter(c.writeMemory[:0])
+		w.BeginMessage(uint8(RestoreBlock))
+		w.PushBytes(block)
+		w.EndMessage()
+
+		if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+			return &clientConnectionClosedError{err: e}
+		}
+	}
+
+	w = buff.NewWriter(c.writeMemory[:0])
+	w.BeginMessage(uint8(RestoreEOF))
+	w.EndMessage()
+
+	w.BeginMessage(uint8(Sync))
+	w.EndMessage()
+
+	if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+		return &clientConnectionClosedError{err: e}
+	}
+
+	done := buff.NewSignal()
+
+	for r.Next(done.Chan) {
+		switch Message(r.MsgType) {
+		case CommandComplete:
+			r.DiscardMessage()
+		case ReadyForCommand:
+			decodeReadyForCommandMsg(r)
+			done.Signal()
+		case ErrorResponse:
+			err = wrapAll(err, decodeErrorResponseMsg(r, ""))
+		default:
+			if e := c.fallThrough(r); e != nil {
+				// the connection will not be usable after this x_x
+				return e
+			}
+		}
+	}
+
+	return wrapAll(err, r.Err)
+}
+
+// waitForRestoreReady reads messages until the server is ready to receive
+// data blocks. If the server rejects the restore ready is false and err
+// explains why.
+func (c *protocolConnection) waitForRestoreReady(
+	r *buff.Reader,
+) (ready bool, err error) {
+	done := buff.NewSignal()
+
+	for r.Next(done.Chan) {
+		switch Message(r.MsgType) {
+		case RestoreReady:
+			ignoreHeaders(r)
+			r.Discard(2) // jobs
+			ready = true
+			done.Signal()
+		case ReadyForCommand:
+			decodeReadyForCommandMsg(r)
+			done.Signal()
+		case ErrorResponse:
+			err = wrapAll(err, decodeErrorResponseMsg(r, ""))
+
+			// The server ignores all messages after an error
+			// until it receives a Sync message.
+			w := buff.NewWriter(c.writeMemory[:0])
+			w.BeginMessage(uint8(Sync))
+			w.EndMessage()
+
+			if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+				return false, &clientConnectionClosedError{err: e}
+			}
+		default:
+			if e := c.fallThrough(r); e != nil {
+				// the connection will not be usable after this x_x
+				return false, e
+			}
+		}
+	}
+
+	if r.Err != nil {
+		return false, wrapAll(err, r.Err)
+	}
+
+	if !ready && err == nil {
+		err = &unexpectedMessageError{
+			msg: "restore ended without receiving RestoreReady",
+		}
+	}
+
+	return ready, err
+}
+
+func writeDumpFileHeader(out io.Writer) error {
+	buf := make([]byte, len(dumpMagic)+8)
+	copy(buf, dumpMagic)
+	binary.BigEndian.PutUint64(buf[len(dumpMagic):], dumpFormatVersion)
+
+	_, err := out.Write(buf)
+	return err
+}
+
+func writeDumpPacket(out io.Writer, typ byte, data []byte) error {
+	sum := sha1.Sum(data)
+
+	buf := make([]byte, 1+len(sum)+4)
+	buf[0] = typ
+	copy(buf[1:], sum[:])
+	binary.BigEndian.PutUint32(buf[1+len(sum):], uint32(len(data)))
+
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+
+	_, err := out.Write(data)
+	return err
+}
+
+func readDumpFileHeader(in io.Reader) error {
+	buf := make([]byte, len(dumpMagic)+8)
+	if _, err := io.ReadFull(in, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return &invalidArgumentError{
+				msg: "the dump file is invalid: incomplete file header",
+			}
+		}
+		return err
+	}
+
+	if !bytes.Equal(buf[:len(dumpMagic)], dumpMagic) {
+		return &invalidArgumentError{
+			msg: "the dump file is invalid: incorrect file header",
+		}
+	}
+
+	version := binary.BigEndian.Uint64(buf[len(dumpMagic):])
+	if version > dumpFormatVersion {
+		return &unsupportedFeatureError{msg: fmt.Sprintf(
+			"unsupported dump format version %v", version)}
+	}
+
+	return nil
+}
+
+// readDumpPacket returns the type and data of the next packet in a dump
+// file. io.EOF is returned if there are no more packets.
+func readDumpPacket(in io.Reader) (byte, []byte, error) {
+	var header [1 + sha1.Size + 4]byte
+	if _, err := io.ReadFull(in, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, &invalidArgumentError{
+				msg: "the dump file is invalid: incomplete packet header",
+			}
+		}
+		return 0, nil, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(header[1+sha1.Size:]))
+	if _, err := io.ReadFull(in, data); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, &invalidArgumentError{
+				msg: "the dump file is invalid: incomplete packet",
+			}
+		}
+		return 0, nil, err
+	}
+
+	sum := sha1.Sum(data)
+	if !bytes.Equal(sum[:], header[1:1+sha1.Size]) {
+		return 0, nil, &invalidArgumentError{
+			msg: "the dump file is invalid: packet checksum mismatch",
+		}
+	}
+
+	return header[0], data, nil
+}
@@ -0,0 +1,105 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpFileFormat(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeDumpFileHeader(&buf))
+	require.NoError(t, writeDumpPacket(&buf, dumpPacketHeader, []byte("abc")))
+	require.NoError(t, writeDumpPacket(&buf, dumpPacketBlock, []byte{}))
+
+	in := bytes.NewReader(buf.Bytes())
+	require.NoError(t, readDumpFileHeader(in))
+
+	typ, data, err := readDumpPacket(in)
+	require.NoError(t, err)
+	assert.Equal(t, byte(dumpPacketHeader), typ)
+	assert.Equal(t, []byte("abc"), data)
+
+	typ, data, err = readDumpPacket(in)
+	require.NoError(t, err)
+	assert.Equal(t, byte(dumpPacketBlock), typ)
+	assert.Equal(t, []byte{}, data)
+
+	_, _, err = readDumpPacket(in)
+	assert.Equal(t, io.EOF, err)
+
+	// corrupt the first packet's data
+	corrupted := buf.Bytes()
+	corrupted[len(dumpMagic)+8+1+20+4] = 'x'
+	in = bytes.NewReader(corrupted)
+	require.NoError(t, readDumpFileHeader(in))
+	_, _, err = readDumpPacket(in)
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"the dump file is invalid: packet checksum mismatch")
+
+	err = readDumpFileHeader(bytes.NewReader([]byte("not a dump file..")))
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"the dump file is invalid: incomplete file header")
+}
+
+func TestDumpRestore(t *testing.T) {
+	ctx := context.Background()
+	src, err := CreateClient(ctx, opts)
+	require.NoError(t, err)
+	defer src.Close() // nolint:errcheck
+
+	var dump bytes.Buffer
+	err = src.Dump(ctx, &dump)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(dump.Bytes(), dumpMagic))
+
+	dbName := fmt.Sprintf("restore%v", rand.Intn(10_000))
+	err = client.Execute(ctx, "CREATE DATABASE "+dbName)
+	require.NoError(t, err)
+
+	o := opts
+	o.Database = dbName
+	dst, err := CreateClient(ctx, o)
+	require.NoError(t, err)
+	defer dst.Close() // nolint:errcheck
+
+	err = dst.Restore(ctx, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+
+	query := "SELECT count(schema::ObjectType " +
+		"FILTER NOT .builtin AND NOT .internal)"
+
+	var expected, result int64
+	require.NoError(t, src.QuerySingle(ctx, query, &expected))
+	require.NoError(t, dst.QuerySingle(ctx, query, &result))
+	assert.Equal(t, expected, result)
+
+	// The database is no longer empty so restoring again is an error.
+	err = dst.Restore(ctx, bytes.NewReader(dump.Bytes()))
+	assert.Error(t, err)
+
+	// The connection is usable after a failed restore.
+	require.NoError(t, dst.QuerySingle(ctx, query, &result))
+}
@@ -35,6 +35,9 @@ type cacheCollection struct {
 	inCodecCache      *cache.Cache
 	outCodecCache     *cache.Cache
 	capabilitiesCache *cache.Cache // nolint:structcheck
+
+	// customScalars are used when building V2 codecs.
+	customScalars *codecs.ScalarRegistry
 }
 
 type protocolConnection struct {
@@ -48,6 +51,9 @@ type protocolConnection struct {
 
 	systemConfig systemConfig
 	stateCodec   codecs.Encoder
+
+	// serverLogHandler is invoked with LogMessages from the server.
+	serverLogHandler ServerLogHandler
 }
 
 // connectWithTimeout makes a single attempt to connect to `addr`.
@@ -72,6 +78,7 @@ func connectWithTimeout(
 		acquireReaderSignal: make(chan struct{}, 1),
 		readerChan:          make(chan *buff.Reader, 1),
 		cacheCollection:     caches,
+		serverLogHandler:    cfg.serverLogHandler,
 	}
 
 	toBeDeserialized := make(chan *soc.Data, 2)
@@ -155,6 +162,101 @@ func (c *protocolConnection) releaseReader(r *buff.Reader) error {
 	return nil
 }
 
+// cancelOnDone stops the query running on the connection if ctx is done
+// before the returned stop function is called. The protocol has no message
+// for cancelling a query, instead the server cancels the query when the
+// connection is closed. Calling stop waits until the connection is no longer
+// at risk of being closed.
+func (c *protocolConnection) cancelOnDone(ctx context.Context) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	stopChan := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			if err := c.soc.Close(); err != nil {
+				log.Println("error closing socket:", err)
+			}
+		case <-stopChan:
+		}
+	}()
+
+	return func() {
+		close(stopChan)
+		<-stopped
+	}
+}
+
+// cancelledError returns ctx's error if err was caused by cancelOnDone()
+// closing the connection.
+func (c *protocolConnection) cancelledError(
+	ctx context.Context,
+	err error,
+) error {
+	if err != nil && ctx.Err() != nil && c.soc.Closed() {
+		return wrapNetError(ctx.Err())
+	}
+
+	return err
+}
+
+// ping checks that the server is still answering by sending a Sync message
+// and waiting for the server to be ready for a command.
+func (c *protocolConnection) ping(ctx context.Context) error {
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	stop := c.cancelOnDone(ctx)
+	err = c.execSync(r)
+	stop()
+
+	err = c.cancelledError(ctx, err)
+	return firstError(err, c.releaseReader(r))
+}
+
+func (c *protocolConnection) execSync(r *buff.Reader) error {
+	w := buff.NewWriter(c.writeMemory[:0])
+	w.BeginMessage(uint8(Sync))
+	w.EndMessage()
+
+	if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+		return &clientConnectionClosedError{err: e}
+	}
+
+	var err error
+	done := buff.NewSignal()
+
+	for r.Next(done.Chan) {
+		switch Message(r.MsgType) {
+		case ReadyForCommand:
+			decodeReadyForCommandMsg(r)
+			done.Signal()
+		case ErrorResponse:
+			err = wrapAll(err, decodeErrorResponseMsg(r, ""))
+		default:
+			if e := c.fallThrough(r); e != nil {
+				// the connection will not be usable after this x_x
+				return e
+			}
+		}
+	}
+
+	return wrapAll(err, r.Err)
+}
+
 // Close the db connection
 func (c *protocolConnection) close() error {
 	if c.soc == nil {
@@ -201,6 +303,8 @@ func (c *protocolConnection) scriptFlow(ctx context.Context, q *query) error {
 		return err
 	}
 
+	restore := c.useServerLogHandler(q.serverLogHandler)
+	stop := c.cancelOnDone(ctx)
 	switch {
 	case c.protocolVersion.GTE(protocolVersion2p0):
 		err = c.execGranularFlow2pX(r, q)
@@ -209,7 +313,10 @@ func (c *protocolConnection) scriptFlow(ctx context.Context, q *query) error {
 	default:
 		err = c.execScriptFlow(r, q)
 	}
+	stop()
+	restore()
 
+	err = c.cancelledError(ctx, err)
 	return firstError(err, c.releaseReader(r))
 }
 
@@ -235,6 +342,8 @@ func (c *protocolConnection) granularFlow(
 		return err
 	}
 
+	restore := c.useServerLogHandler(q.serverLogHandler)
+	stop := c.cancelOnDone(ctx)
 	switch {
 	case c.protocolVersion.GTE(protocolVersion2p0):
 		err = c.execGranularFlow2pX(r, q)
@@ -243,6 +352,9 @@ func (c *protocolConnection) granularFlow(
 	default:
 		err = c.execGranularFlow0pX(r, q)
 	}
+	stop()
+	restore()
 
+	err = c.cancelledError(ctx, err)
 	return firstError(err, c.releaseReader(r))
 }
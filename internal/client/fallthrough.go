@@ -18,7 +18,6 @@ package edgedb
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 	"strconv"
 	"unsafe"
@@ -28,13 +27,6 @@ import (
 	"github.com/edgedb/edgedb-go/internal/descriptor"
 )
 
-var logMsgSeverityLookup = map[uint8]string{
-	0x14: "DEBUG",
-	0x28: "INFO",
-	0x3c: "NOTICE",
-	0x50: "WARNING",
-}
-
 func (c *protocolConnection) fallThrough(r *buff.Reader) error {
 	if c.protocolVersion.GTE(protocolVersion2p0) {
 		return c.fallThrough2pX(r)
@@ -93,11 +85,7 @@ func (c *protocolConnection) fallThrough(r *buff.Reader) error {
 				"got ParameterStatus for unknown parameter %q", name)}
 		}
 	case LogMessage:
-		severity := logMsgSeverityLookup[r.PopUint8()]
-		code := r.PopUint32()
-		message := r.PopString()
-		ignoreHeaders(r)
-		log.Println("SERVER MESSAGE", severity, code, message)
+		c.decodeLogMessage(r)
 	default:
 		msg := fmt.Sprintf("unexpected message type: 0x%x", r.MsgType)
 		return &unexpectedMessageError{msg: msg}
@@ -142,6 +130,7 @@ func (c *protocolConnection) fallThrough2pX(r *buff.Reader) error {
 				&desc,
 				reflect.TypeOf(cfg),
 				codecs.Path("system_config"),
+				nil,
 			)
 			if err != nil {
 				return &binaryProtocolError{err: fmt.Errorf(
@@ -163,11 +152,7 @@ func (c *protocolConnection) fallThrough2pX(r *buff.Reader) error {
 				"got ParameterStatus for unknown parameter %q", name)}
 		}
 	case LogMessage:
-		severity := logMsgSeverityLookup[r.PopUint8()]
-		code := r.PopUint32()
-		message := r.PopString()
-		ignoreHeaders(r)
-		log.Println("SERVER MESSAGE", severity, code, message)
+		c.decodeLogMessage(r)
 	default:
 		msg := fmt.Sprintf("unexpected message type: 0x%x", r.MsgType)
 		return &unexpectedMessageError{msg: msg}
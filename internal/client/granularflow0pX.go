@@ -167,7 +167,7 @@ func (c *protocolConnection) codecsFromDescriptors0pX(
 
 func (c *protocolConnection) prepare0pX(r *buff.Reader, q *query) error {
 	headers := q.headers0pX()
-	headers[header.ExplicitObjectIDs] = []byte("true")
+	q.compilation.addHeaders0pX(headers)
 
 	w := buff.NewWriter(c.writeMemory[:0])
 	w.BeginMessage(uint8(Parse))
@@ -344,7 +344,7 @@ func (c *protocolConnection) optimistic0pX(
 	cdcs *codecPair,
 ) (*CommandDescription, error) {
 	headers := q.headers0pX()
-	headers[header.ExplicitObjectIDs] = []byte("true")
+	q.compilation.addHeaders0pX(headers)
 
 	w := buff.NewWriter(c.writeMemory[:0])
 	w.BeginMessage(uint8(Execute))
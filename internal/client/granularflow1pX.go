@@ -67,8 +67,8 @@ func (c *protocolConnection) parse1pX(
 	w.BeginMessage(uint8(Parse))
 	w.PushUint16(0) // no headers
 	w.PushUint64(q.capabilities)
-	w.PushUint64(0) // no compilation_flags
-	w.PushUint64(0) // no implicit limit
+	w.PushUint64(q.compilation.flags())
+	w.PushUint64(q.compilation.implicitLimit)
 	w.PushUint8(uint8(q.fmt))
 	w.PushUint8(uint8(q.expCard))
 	w.PushString(q.cmd)
@@ -186,8 +186,8 @@ func (c *protocolConnection) execute1pX(
 	w.BeginMessage(uint8(Execute))
 	w.PushUint16(0) // no headers
 	w.PushUint64(q.capabilities)
-	w.PushUint64(0) // no compilation_flags
-	w.PushUint64(0) // no implicit limit
+	w.PushUint64(q.compilation.flags())
+	w.PushUint64(q.compilation.implicitLimit)
 	w.PushUint8(uint8(q.fmt))
 	w.PushUint8(uint8(q.expCard))
 	w.PushString(q.cmd)
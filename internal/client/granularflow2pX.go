@@ -23,6 +23,7 @@ import (
 	"github.com/edgedb/edgedb-go/internal/buff"
 	"github.com/edgedb/edgedb-go/internal/codecs"
 	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
 	"github.com/edgedb/edgedb-go/internal/state"
 )
 
@@ -30,6 +31,10 @@ func (c *protocolConnection) execGranularFlow2pX(
 	r *buff.Reader,
 	q *query,
 ) error {
+	if q.prepared != nil {
+		return c.execute2pX(r, q, q.prepared)
+	}
+
 	var cdcs *codecPair
 	if q.parse {
 		ids, ok := c.getCachedTypeIDs(q)
@@ -73,8 +78,8 @@ func (c *protocolConnection) parse2pX(
 	w.BeginMessage(uint8(Parse))
 	w.PushUint16(0) // no headers
 	w.PushUint64(q.capabilities)
-	w.PushUint64(0) // no compilation_flags
-	w.PushUint64(0) // no implicit limit
+	w.PushUint64(q.compilation.flags())
+	w.PushUint64(q.compilation.implicitLimit)
 	if c.protocolVersion.GTE(protocolVersion3p0) {
 		w.PushUint8(uint8(q.lang))
 	}
@@ -192,30 +197,10 @@ func (c *protocolConnection) execute2pX(
 	cdcs *codecPair,
 ) error {
 	w := buff.NewWriter(c.writeMemory[:0])
-	w.BeginMessage(uint8(Execute))
-	w.PushUint16(0) // no headers
-	w.PushUint64(q.capabilities)
-	w.PushUint64(0) // no compilation_flags
-	w.PushUint64(0) // no implicit limit
-	if c.protocolVersion.GTE(protocolVersion3p0) {
-		w.PushUint8(uint8(q.lang))
-	}
-	w.PushUint8(uint8(q.fmt))
-	w.PushUint8(uint8(q.expCard))
-	w.PushString(q.cmd)
-	w.PushUUID(c.stateCodec.DescriptorID())
-	err := c.stateCodec.Encode(w, q.state, codecs.Path("state"), false)
+	err := c.encodeExecute2pX(w, q, cdcs.in, cdcs.out.DescriptorID())
 	if err != nil {
-		return &binaryProtocolError{err: fmt.Errorf(
-			"invalid connection state: %w", err)}
-	}
-
-	w.PushUUID(cdcs.in.DescriptorID())
-	w.PushUUID(cdcs.out.DescriptorID())
-	if e := cdcs.in.Encode(w, q.args, codecs.Path("args"), true); e != nil {
-		return &invalidArgumentError{msg: e.Error()}
+		return err
 	}
-	w.EndMessage()
 
 	w.BeginMessage(uint8(Sync))
 	w.EndMessage()
@@ -224,6 +209,17 @@ func (c *protocolConnection) execute2pX(
 		return &clientConnectionClosedError{err: e}
 	}
 
+	return c.decodeExecuteResult2pX(r, q, cdcs)
+}
+
+// decodeExecuteResult2pX reads the response to an Execute message
+// up to and including the next ReadyForCommand message.
+func (c *protocolConnection) decodeExecuteResult2pX(
+	r *buff.Reader,
+	q *query,
+	cdcs *codecPair,
+) error {
+	var err error
 	tmp := q.out
 	if q.expCard == AtMostOne {
 		err = errZeroResults
@@ -241,6 +237,10 @@ func (c *protocolConnection) execute2pX(
 			err = wrapAll(err, e)
 			cdcs, e = c.codecsFromDescriptors2pX(q, descs)
 			err = wrapAll(err, e)
+			if e == nil && q.prepared != nil {
+				// The PreparedQuery keeps the new codecs.
+				q.prepared = cdcs
+			}
 		case Data:
 			val, ok, e := decodeDataMsg(r, q, cdcs)
 			if e != nil {
@@ -289,6 +289,41 @@ func (c *protocolConnection) execute2pX(
 	return err
 }
 
+// encodeExecute2pX writes an Execute message for q to w.
+func (c *protocolConnection) encodeExecute2pX(
+	w *buff.Writer,
+	q *query,
+	in codecs.Encoder,
+	outID types.UUID,
+) error {
+	w.BeginMessage(uint8(Execute))
+	w.PushUint16(0) // no headers
+	w.PushUint64(q.capabilities)
+	w.PushUint64(q.compilation.flags())
+	w.PushUint64(q.compilation.implicitLimit)
+	if c.protocolVersion.GTE(protocolVersion3p0) {
+		w.PushUint8(uint8(q.lang))
+	}
+	w.PushUint8(uint8(q.fmt))
+	w.PushUint8(uint8(q.expCard))
+	w.PushString(q.cmd)
+	w.PushUUID(c.stateCodec.DescriptorID())
+	err := c.stateCodec.Encode(w, q.state, codecs.Path("state"), false)
+	if err != nil {
+		return &binaryProtocolError{err: fmt.Errorf(
+			"invalid connection state: %w", err)}
+	}
+
+	w.PushUUID(in.DescriptorID())
+	w.PushUUID(outID)
+	if e := in.Encode(w, q.args, codecs.Path("args"), true); e != nil {
+		return &invalidArgumentError{msg: e.Error()}
+	}
+	w.EndMessage()
+
+	return nil
+}
+
 func (c *protocolConnection) codecsFromIDsV2(
 	ids *idPair,
 	q *query,
@@ -303,7 +338,7 @@ func (c *protocolConnection) codecsFromIDsV2(
 		}
 
 		d := desc.(descriptor.V2)
-		in, err = codecs.BuildEncoderV2(&d, c.protocolVersion)
+		in, err = codecs.BuildEncoderV2(&d, c.protocolVersion, c.customScalars)
 		if err != nil {
 			return nil, &invalidArgumentError{msg: err.Error()}
 		}
@@ -318,7 +353,12 @@ func (c *protocolConnection) codecsFromIDsV2(
 
 		d := desc.(descriptor.V2)
 		path := codecs.Path(q.outType.String())
-		out, err = codecs.BuildDecoderV2(&d, q.outType, path)
+		out, err = codecs.BuildDecoderV2(
+			&d,
+			q.outType,
+			path,
+			c.customScalars,
+		)
 		if err != nil {
 			return nil, &invalidArgumentError{msg: fmt.Sprintf(
 				"the \"out\" argument does not match query schema: %v", err)}
@@ -334,7 +374,11 @@ func (c *protocolConnection) codecsFromDescriptors2pX(
 ) (*codecPair, error) {
 	var cdcs codecPair
 	var err error
-	cdcs.in, err = codecs.BuildEncoderV2(&descs.In, c.protocolVersion)
+	cdcs.in, err = codecs.BuildEncoderV2(
+		&descs.In,
+		c.protocolVersion,
+		c.customScalars,
+	)
 	if err != nil {
 		return nil, &invalidArgumentError{msg: err.Error()}
 	}
@@ -350,7 +394,12 @@ func (c *protocolConnection) codecsFromDescriptors2pX(
 			path = codecs.Path(q.outType.String())
 		}
 
-		cdcs.out, err = codecs.BuildDecoderV2(&descs.Out, q.outType, path)
+		cdcs.out, err = codecs.BuildDecoderV2(
+			&descs.Out,
+			q.outType,
+			path,
+			c.customScalars,
+		)
 		if err != nil {
 			err = fmt.Errorf(
 				"the \"out\" argument does not match query schema: %v",
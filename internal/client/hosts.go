@@ -0,0 +1,218 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HostOrder determines the order in which a client tries to connect to
+// multiple hosts.
+type HostOrder string
+
+const (
+	// HostOrderInOrder tries hosts in the order they are listed.
+	HostOrderInOrder HostOrder = "in_order"
+	// HostOrderRandom tries hosts in a random order
+	// to spread connections across hosts.
+	HostOrderRandom HostOrder = "random"
+)
+
+const (
+	hostBackoffMin = 100 * time.Millisecond
+	hostBackoffMax = 30 * time.Second
+)
+
+func parseHostOrder(val string) (HostOrder, error) {
+	switch HostOrder(val) {
+	case HostOrderInOrder, HostOrderRandom:
+		return HostOrder(val), nil
+	default:
+		return "", fmt.Errorf(
+			"invalid host order %q, expected %v or %v",
+			val, HostOrderInOrder, HostOrderRandom)
+	}
+}
+
+// isHostList returns true if host is a comma separated list of hosts.
+func isHostList(host string) bool {
+	return strings.Contains(host, ",")
+}
+
+// parseHostList parses a comma separated list of hosts. Each host can have
+// a port, hosts without a port use port. Absolute paths are unix domain
+// sockets.
+func parseHostList(val string, port int) ([]dialArgs, error) {
+	entries := strings.Split(val, ",")
+	addrs := make([]dialArgs, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if isUnixSocketPath(entry) {
+			addrs = append(addrs, dialArgs{"unix", entry})
+			continue
+		}
+
+		host, p := entry, port
+		if h, ps, err := net.SplitHostPort(entry); err == nil {
+			host = h
+			p, err = strconv.Atoi(ps)
+			if err != nil || p < 1 || p > 65535 {
+				return nil, fmt.Errorf("invalid port %q in host %q", ps, entry)
+			}
+		}
+
+		if host == "" || strings.ContainsAny(host, "/[]") {
+			return nil, fmt.Errorf("invalid host: %q", entry)
+		}
+
+		addrs = append(addrs, dialArgs{
+			network: "tcp",
+			address: net.JoinHostPort(host, strconv.Itoa(p)),
+		})
+	}
+
+	return addrs, nil
+}
+
+// hostList tracks connection failures for each of a client's hosts so that
+// hosts that are down are tried last. It is shared by all of a client's
+// connections.
+type hostList struct {
+	order HostOrder
+
+	mu    sync.Mutex
+	hosts []*hostState
+}
+
+type hostState struct {
+	addr dialArgs
+
+	// failures is the number of consecutive failed connection attempts.
+	failures int
+
+	// retryAt is when the host should be tried again after a failure.
+	retryAt time.Time
+}
+
+func newHostList(addrs []dialArgs, order HostOrder) *hostList {
+	hosts := make([]*hostState, len(addrs))
+	for i, addr := range addrs {
+		hosts[i] = &hostState{addr: addr}
+	}
+
+	return &hostList{order: order, hosts: hosts}
+}
+
+// candidates returns the hosts in the order they should be tried. Hosts that
+// recently failed are tried after the other hosts in the order they can be
+// retried.
+func (l *hostList) candidates() []dialArgs {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hosts := make([]*hostState, len(l.hosts))
+	copy(hosts, l.hosts)
+
+	if l.order == HostOrderRandom {
+		for i := len(hosts) - 1; i > 0; i-- {
+			j := rnd.Intn(i + 1)
+			hosts[i], hosts[j] = hosts[j], hosts[i]
+		}
+	}
+
+	now := time.Now()
+	sort.SliceStable(hosts, func(i, j int) bool {
+		iReady := !hosts[i].retryAt.After(now)
+		jReady := !hosts[j].retryAt.After(now)
+		if iReady || jReady {
+			return iReady && !jReady
+		}
+
+		return hosts[i].retryAt.Before(hosts[j].retryAt)
+	})
+
+	addrs := make([]dialArgs, len(hosts))
+	for i, host := range hosts {
+		addrs[i] = host.addr
+	}
+
+	return addrs
+}
+
+func (l *hostList) find(addr dialArgs) *hostState {
+	for _, host := range l.hosts {
+		if host.addr == addr {
+			return host
+		}
+	}
+
+	return nil
+}
+
+// failed backs off from addr exponentially.
+func (l *hostList) failed(addr dialArgs) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	host := l.find(addr)
+	if host == nil {
+		return
+	}
+
+	backoff := hostBackoffMax
+	if host.failures < 16 {
+		backoff = hostBackoffMin << host.failures
+		if backoff > hostBackoffMax {
+			backoff = hostBackoffMax
+		}
+	}
+
+	host.failures++
+	host.retryAt = time.Now().Add(backoff)
+}
+
+// succeeded resets addr's backoff.
+func (l *hostList) succeeded(addr dialArgs) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if host := l.find(addr); host != nil {
+		host.failures = 0
+		host.retryAt = time.Time{}
+	}
+}
+
+// replicaConfig returns a copy of cfg that connects to the read replicas.
+func (c *connConfig) replicaConfig() *connConfig {
+	cfg := *c
+	cfg.addr = c.readReplicas[0]
+	cfg.fallbackAddrs = nil
+	if len(c.readReplicas) > 1 {
+		cfg.fallbackAddrs = c.readReplicas[1:]
+	}
+	cfg.readReplicas = nil
+
+	// The proxy environment variables were validated by config().
+	cfg.proxy, _ = addrProxy(c.dialer, cfg.addr)
+	return &cfg
+}
@@ -0,0 +1,187 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/edgedb/edgedb-go/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostList(t *testing.T) {
+	addrs, err := parseHostList(
+		"db1, db2:5657,/run/edgedb.sock,[::1]:1", 5656)
+	require.NoError(t, err)
+	assert.Equal(t, []dialArgs{
+		{"tcp", "db1:5656"},
+		{"tcp", "db2:5657"},
+		{"unix", "/run/edgedb.sock"},
+		{"tcp", "[::1]:1"},
+	}, addrs)
+
+	_, err = parseHostList("db1,db2:0", 5656)
+	assert.EqualError(t, err, `invalid port "0" in host "db2:0"`)
+
+	_, err = parseHostList("db1,db2/x", 5656)
+	assert.EqualError(t, err, `invalid host: "db2/x"`)
+}
+
+func TestHostListCandidates(t *testing.T) {
+	a := dialArgs{"tcp", "a:5656"}
+	b := dialArgs{"tcp", "b:5656"}
+	c := dialArgs{"tcp", "c:5656"}
+	hosts := newHostList([]dialArgs{a, b, c}, HostOrderInOrder)
+
+	assert.Equal(t, []dialArgs{a, b, c}, hosts.candidates())
+
+	// Hosts that failed are tried last
+	// in the order that their backoff ends.
+	hosts.failed(a)
+	hosts.failed(a)
+	hosts.failed(b)
+	assert.Equal(t, []dialArgs{c, b, a}, hosts.candidates())
+
+	hosts.succeeded(a)
+	assert.Equal(t, []dialArgs{a, c, b}, hosts.candidates())
+
+	// Hosts are available again after their backoff.
+	hosts.hosts[1].retryAt = time.Now().Add(-time.Second)
+	assert.Equal(t, []dialArgs{a, b, c}, hosts.candidates())
+}
+
+func TestHostListRandomOrder(t *testing.T) {
+	addrs := make([]dialArgs, 10)
+	for i := range addrs {
+		addrs[i] = dialArgs{"tcp", fmt.Sprintf("host%v:5656", i)}
+	}
+	hosts := newHostList(addrs, HostOrderRandom)
+
+	shuffled := false
+	for i := 0; i < 10 && !shuffled; i++ {
+		candidates := hosts.candidates()
+		assert.ElementsMatch(t, addrs, candidates)
+		shuffled = candidates[0] != addrs[0]
+	}
+	assert.True(t, shuffled, "hosts were never shuffled")
+
+	// Failed hosts are still tried last.
+	hosts.failed(addrs[3])
+	candidates := hosts.candidates()
+	assert.Equal(t, addrs[3], candidates[len(candidates)-1])
+}
+
+func TestHostListBackoff(t *testing.T) {
+	addr := dialArgs{"tcp", "a:5656"}
+	hosts := newHostList([]dialArgs{addr}, HostOrderInOrder)
+
+	start := time.Now()
+	hosts.failed(addr)
+	assert.WithinDuration(t,
+		start.Add(hostBackoffMin), hosts.hosts[0].retryAt, time.Second)
+
+	for i := 0; i < 100; i++ {
+		hosts.failed(addr)
+	}
+	assert.WithinDuration(t,
+		time.Now().Add(hostBackoffMax), hosts.hosts[0].retryAt, time.Second)
+
+	hosts.succeeded(addr)
+	assert.Equal(t, 0, hosts.hosts[0].failures)
+	assert.True(t, hosts.hosts[0].retryAt.IsZero())
+}
+
+func TestConnectTriesEachHost(t *testing.T) {
+	var dialed []string
+	cfg := &connConfig{
+		addr:          dialArgs{"tcp", "a:5656"},
+		fallbackAddrs: []dialArgs{{"tcp", "b:5656"}},
+		dialer: func(
+			_ context.Context,
+			_, address string,
+		) (net.Conn, error) {
+			dialed = append(dialed, address)
+			return nil, syscall.ECONNREFUSED
+		},
+	}
+
+	conn := &reconnectingConn{
+		cfg:   cfg,
+		hosts: newHostList(cfg.addrs(), HostOrderInOrder),
+	}
+
+	_, err := conn.connect(context.Background(), cfg)
+	assert.True(t, isClientConnectionError(err))
+	assert.Equal(t, []string{"a:5656", "b:5656"}, dialed)
+
+	for _, host := range conn.hosts.hosts {
+		assert.Equal(t, 1, host.failures)
+	}
+}
+
+func TestReplicaConfig(t *testing.T) {
+	cfg := &connConfig{
+		addr:      dialArgs{"tcp", "primary:5656"},
+		hostOrder: HostOrderRandom,
+		readReplicas: []dialArgs{
+			{"tcp", "replica1:5656"},
+			{"tcp", "replica2:5656"},
+		},
+		user: "edgedb",
+	}
+
+	assert.Equal(t, &connConfig{
+		addr:          dialArgs{"tcp", "replica1:5656"},
+		fallbackAddrs: []dialArgs{{"tcp", "replica2:5656"}},
+		hostOrder:     HostOrderRandom,
+		user:          "edgedb",
+	}, cfg.replicaConfig())
+}
+
+func TestRouteReadOnlyQueries(t *testing.T) {
+	caches := cacheCollection{capabilitiesCache: cache.New(10)}
+	primary := &Client{cacheCollection: caches}
+	primary.replicas = &Client{cacheCollection: caches}
+
+	cacheCapabilities := func(cmd string, capabilities uint64) {
+		q, err := newQuery("Query", cmd, nil, 0, nil, &[]int64{}, true, nil,
+			nil, primary.compilation)
+		require.NoError(t, err)
+		caches.capabilitiesCache.Put(makeKey(q), capabilities)
+	}
+	cacheCapabilities("select 1", 0)
+	cacheCapabilities("create type User", capabilitiesDDL)
+
+	var out []int64
+	assert.Same(t, primary, primary.route("Query", "select 1", &out))
+
+	routing := primary.WithReadOnlyRouting()
+	assert.Same(t,
+		primary.replicas, routing.route("Query", "select 1", &out))
+	assert.Same(t, routing, routing.route("Query", "create type User", &out))
+	assert.Same(t, routing, routing.route("Query", "select 2", &out))
+
+	// The output type is part of the cache key.
+	var outStr []string
+	assert.Same(t, routing, routing.route("Query", "select 1", &outStr))
+}
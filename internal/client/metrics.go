@@ -0,0 +1,157 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"sync"
+	"time"
+)
+
+// PoolStats are statistics about a client's connection pool.
+type PoolStats struct {
+	// Open is the number of open connections, both idle and in use.
+	Open int
+
+	// Idle is the number of open connections that are not in use.
+	Idle int
+
+	// InUse is the number of connections that are acquired.
+	InUse int
+
+	// Waiting is the number of callers waiting to acquire a connection.
+	Waiting int
+
+	// Acquired is the total number of times a connection was acquired.
+	Acquired uint64
+
+	// AcquireWait is the total time callers spent acquiring connections.
+	AcquireWait time.Duration
+
+	// IdleClosed is the total number of connections that were closed
+	// because they were not needed.
+	IdleClosed uint64
+
+	// ErroredClosed is the total number of connections that were closed
+	// because of a connection error.
+	ErroredClosed uint64
+
+	// LifetimeClosed is the total number of connections that were closed
+	// because they exceeded Options.MaxConnLifetime.
+	LifetimeClosed uint64
+}
+
+// Metrics is notified of connection pool events. Methods are called
+// synchronously by the client so they should return quickly, and they must
+// be safe for concurrent use.
+type Metrics interface {
+	// OnAcquire is called when a connection is acquired from the pool.
+	// wait is how long it took to acquire the connection.
+	OnAcquire(wait time.Duration)
+
+	// OnRelease is called when an acquired connection is released.
+	OnRelease()
+
+	// OnConnect is called when the pool opens a new connection.
+	OnConnect()
+
+	// OnClose is called when the pool closes a connection.
+	// err is the connection error that caused the connection to be closed,
+	// it is nil if the connection was closed for another reason.
+	OnClose(err error)
+}
+
+type closeReason int
+
+const (
+	closedIdle closeReason = iota
+	closedErrored
+	closedExpired
+	closedClient
+)
+
+// poolStats tracks PoolStats for a client and notifies Metrics.
+// It is shared by all copies of a client.
+type poolStats struct {
+	mu      sync.Mutex
+	stats   PoolStats
+	metrics Metrics
+}
+
+func (s *poolStats) acquired(wait time.Duration) {
+	s.mu.Lock()
+	s.stats.InUse++
+	s.stats.Acquired++
+	s.stats.AcquireWait += wait
+	s.mu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.OnAcquire(wait)
+	}
+}
+
+func (s *poolStats) released() {
+	s.mu.Lock()
+	s.stats.InUse--
+	s.mu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.OnRelease()
+	}
+}
+
+func (s *poolStats) connected() {
+	s.mu.Lock()
+	s.stats.Open++
+	s.mu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.OnConnect()
+	}
+}
+
+func (s *poolStats) closed(reason closeReason, err error) {
+	s.mu.Lock()
+	s.stats.Open--
+	switch reason {
+	case closedIdle:
+		s.stats.IdleClosed++
+	case closedErrored:
+		s.stats.ErroredClosed++
+	case closedExpired:
+		s.stats.LifetimeClosed++
+	}
+	s.mu.Unlock()
+
+	if s.metrics != nil {
+		s.metrics.OnClose(err)
+	}
+}
+
+func (s *poolStats) waiting(delta int) {
+	s.mu.Lock()
+	s.stats.Waiting += delta
+	s.mu.Unlock()
+}
+
+func (s *poolStats) snapshot() PoolStats {
+	s.mu.Lock()
+	stats := s.stats
+	s.mu.Unlock()
+
+	stats.Idle = stats.Open - stats.InUse
+	return stats
+}
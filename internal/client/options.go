@@ -17,6 +17,7 @@
 package edgedb
 
 import (
+	"crypto/tls"
 	"fmt"
 	"math"
 	"time"
@@ -26,8 +27,13 @@ import (
 
 // Options for connecting to an EdgeDB server
 type Options struct {
-	// Host is an EdgeDB server host address, given as either an IP address or
-	// domain name. (Unix-domain socket paths are not supported)
+	// Host is an EdgeDB server host address, given as either an IP address,
+	// domain name or the absolute path of a unix domain socket. Port is
+	// ignored and TLS is not used when connecting to a unix domain socket.
+	//
+	// Host can also be a comma separated list of hosts, each with an
+	// optional port, for example "db1,db2:5657". The hosts are tried in
+	// HostOrder until a connection succeeds. Hosts without a port use Port.
 	//
 	// Host cannot be specified alongside the 'dsn' argument, or
 	// CredentialsFile option. Host will override all other credentials
@@ -43,6 +49,16 @@ type Options struct {
 	// their defaults.
 	Port int
 
+	// HostOrder is the order in which hosts are tried when Host is a list
+	// of hosts. Hosts that recently failed are tried last.
+	// The default is HostOrderInOrder.
+	HostOrder HostOrder
+
+	// ReadReplicas are hosts, each with an optional port, of read only
+	// replicas. Clients created with Client.WithReadOnlyRouting send read
+	// only queries to the replicas.
+	ReadReplicas []string
+
 	// Credentials is a JSON string containing connection credentials.
 	//
 	// Credentials cannot be specified alongside the 'dsn' argument, Host,
@@ -93,6 +109,15 @@ type Options struct {
 	// ConnectTimeout is used when establishing connections in the background.
 	ConnectTimeout time.Duration
 
+	// Dialer opens the network connection to the server, for example through
+	// an SSH tunnel or an in memory net.Pipe. It is called with the resolved
+	// network and address and the TLS handshake is done on the returned
+	// connection. If Dialer is nil, connections are made directly or through
+	// the proxy configured by the HTTPS_PROXY or ALL_PROXY environment
+	// variables, which can be http://, https://, socks5:// or socks5h:// URLs.
+	// Hosts listed in NO_PROXY and loopback addresses are not proxied.
+	Dialer DialFunc
+
 	// WaitUntilAvailable determines how long to wait
 	// to reestablish a connection.
 	WaitUntilAvailable time.Duration
@@ -119,9 +144,43 @@ type Options struct {
 	// SecretKey is used to connect to cloud instances.
 	SecretKey string
 
+	// CredentialsProvider is called for the user, password and secret key
+	// each time a new connection is opened. Credentials it returns take
+	// precedence over User, Password and SecretKey. Optional.
+	CredentialsProvider CredentialsProvider
+
 	// WarningHandler is invoked when EdgeDB returns warnings. Defaults to
 	// edgedb.LogWarnings.
 	WarningHandler WarningHandler
+
+	// ServerLogHandler is invoked when EdgeDB sends log messages.
+	// Defaults to edgedb.LogServerMessages.
+	ServerLogHandler ServerLogHandler
+
+	// Metrics is notified of connection pool events. Optional.
+	Metrics Metrics
+
+	// MinIdleConns is the number of idle connections that the client keeps
+	// open so that queries don't have to wait for a new connection.
+	// The connections are opened in the background after the client is
+	// created. The number of connections is still limited by Concurrency.
+	MinIdleConns uint
+
+	// MaxConnLifetime is how long a connection is used before it is closed
+	// and replaced. Each connection's lifetime is shortened by a random amount
+	// of up to 10% so that connections are not all replaced at once.
+	// If MaxConnLifetime is zero connections are used until they are closed
+	// for another reason.
+	MaxConnLifetime time.Duration
+
+	// HealthCheckInterval is how often idle connections are checked in the
+	// background by sending the server a Sync message. Idle connections
+	// that don't get an answer or that exceeded MaxConnLifetime are closed
+	// and replaced if needed to keep MinIdleConns connections open.
+	// If HealthCheckInterval is zero it defaults to 30 seconds when
+	// MinIdleConns or MaxConnLifetime is set, otherwise health checks are
+	// disabled.
+	HealthCheckInterval time.Duration
 }
 
 // TLSOptions contains the parameters needed to configure TLS on EdgeDB
@@ -135,6 +194,24 @@ type TLSOptions struct {
 	SecurityMode TLSSecurityMode
 	// Used to verify the hostname on the returned certificates
 	ServerName string
+	// PEM-encoded client certificate, used when the server or a proxy in
+	// front of it requires client certificate authentication. It can also
+	// contain the client key.
+	ClientCert []byte
+	// Path to a PEM-encoded client certificate file
+	ClientCertFile string
+	// PEM-encoded client private key. Required unless ClientCert or
+	// ClientCertFile also contains the key.
+	ClientKey []byte
+	// Path to a PEM-encoded client private key file
+	ClientKeyFile string
+	// GetClientCertificate is called when the server requests a client
+	// certificate. It can be used instead of ClientCert to rotate
+	// certificates without recreating the client. Like ClientCert it takes
+	// precedence over certificates from the DSN or environment variables.
+	// See tls.Config.GetClientCertificate.
+	GetClientCertificate func(*tls.CertificateRequestInfo) (
+		*tls.Certificate, error)
 }
 
 // TLSSecurityMode specifies how strict TLS validation is.
@@ -531,3 +608,67 @@ func (p Client) WithWarningHandler( // nolint:gocritic
 	p.warningHandler = warningHandler
 	return &p
 }
+
+// WithImplicitLimit sets the maximum number of results returned by the
+// returned client's queries. Results past the limit are silently discarded
+// by the server. A limit of 0 disables the implicit limit.
+func (p Client) WithImplicitLimit(limit uint64) *Client { // nolint:gocritic
+	p.compilation.implicitLimit = limit
+	return &p
+}
+
+// WithImplicitTypeIDs sets whether the server adds a __tid__ property with
+// the object's type id to objects returned by the returned client's queries.
+// The property can be decoded into a types.UUID struct field tagged
+// `edgedb:"__tid__"`.
+func (p Client) WithImplicitTypeIDs(inject bool) *Client { // nolint:gocritic
+	p.compilation.implicitTypeIDs = inject
+	return &p
+}
+
+// WithImplicitTypeNames sets whether the server adds a __tname__ property
+// with the object's type name to objects returned by the returned client's
+// queries. The property can be decoded into a string struct field tagged
+// `edgedb:"__tname__"`.
+func (p Client) WithImplicitTypeNames( // nolint:gocritic
+	inject bool,
+) *Client {
+	p.compilation.implicitTypeNames = inject
+	return &p
+}
+
+// WithExplicitObjectIDs sets whether object ids are only returned when they
+// are selected explicitly. It is true by default. If explicit is false the
+// server adds an id property to objects returned by the returned client's
+// queries.
+func (p Client) WithExplicitObjectIDs( // nolint:gocritic
+	explicit bool,
+) *Client {
+	p.compilation.implicitObjectIDs = !explicit
+	return &p
+}
+
+// WithServerLogHandler sets the server log handler for the returned client.
+// Log messages received while running the client's queries are passed to
+// handler. If handler is nil edgedb.LogServerMessages is used.
+func (p Client) WithServerLogHandler( // nolint:gocritic
+	handler ServerLogHandler,
+) *Client {
+	if handler == nil {
+		handler = LogServerMessages
+	}
+
+	p.serverLogHandler = handler
+	return &p
+}
+
+// WithReadOnlyRouting returns a shallow copy of the client that runs read
+// only queries on the hosts in Options.ReadReplicas. A query is known to be
+// read only after it has run once and its capabilities are cached, so the
+// first run of a query always uses the primary. Execute, Tx, writes and
+// queries with unknown capabilities use the primary. Without read replicas
+// all queries use the primary.
+func (p Client) WithReadOnlyRouting() *Client { // nolint:gocritic
+	p.readOnlyRouting = true
+	return &p
+}
@@ -0,0 +1,178 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// maintain runs health checks on idle connections and opens connections
+// to keep MinIdleConns idle connections until the client is closed.
+func (p *Client) maintain() {
+	ticker := time.NewTicker(p.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		p.checkIdleConns()
+		p.fillIdleConns()
+
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// checkIdleConns closes idle connections that are broken, that don't answer
+// a Sync message within healthCheckTimeout or that have exceeded their
+// lifetime.
+func (p *Client) checkIdleConns() {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	ctx, cancel := p.backgroundContext()
+	defer cancel()
+
+	for i := len(p.freeConns); i > 0; i-- {
+		var conn *transactableConn
+		select {
+		case acquireIfNotTimedout := <-p.freeConns:
+			conn = acquireIfNotTimedout()
+		default:
+			return
+		}
+
+		if conn == nil {
+			// the connection was closed by its idle timeout.
+			continue
+		}
+
+		var err error
+		switch {
+		case conn.conn == nil || conn.conn.isClosed():
+			err = p.closeConn(
+				conn,
+				closedErrored,
+				&clientConnectionClosedError{
+					msg: "the idle connection was closed",
+				},
+			)
+		case conn.expired():
+			err = p.closeConn(conn, closedExpired, nil)
+		default:
+			pingCtx, cancelPing := context.WithTimeout(ctx, healthCheckTimeout)
+			err = conn.conn.ping(pingCtx)
+			cancelPing()
+
+			if err != nil {
+				err = p.closeConn(conn, closedErrored, err)
+			} else {
+				err = p.putIdle(conn)
+			}
+		}
+
+		if err != nil && !isClientConnectionError(err) {
+			log.Println("error during connection health check:", err)
+		}
+	}
+}
+
+// fillIdleConns opens new connections
+// until there are at least MinIdleConns idle connections
+// or the pool's concurrency limit is reached.
+func (p *Client) fillIdleConns() {
+	if p.minIdleConns == 0 {
+		return
+	}
+
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	ctx, cancel := p.backgroundContext()
+	defer cancel()
+
+	p.potentialConnsMutext.Lock()
+	initialized := p.potentialConns != nil
+	p.potentialConnsMutext.Unlock()
+
+	if !initialized {
+		// The first connection determines the pool's concurrency.
+		if err := p.EnsureConnected(ctx); err != nil {
+			log.Println("error while opening idle connection:", err)
+			return
+		}
+	}
+
+	for len(p.freeConns) < p.minIdleConns {
+		select {
+		case <-p.potentialConns:
+		default:
+			return
+		}
+
+		conn, err := p.newConn(ctx)
+		if err != nil {
+			p.potentialConns <- struct{}{}
+			log.Println("error while opening idle connection:", err)
+			return
+		}
+
+		p.stats.connected()
+
+		// Close() closes done before it sets isClosed and then waits for
+		// this connection while holding isClosedMutex, so done is checked
+		// instead of isClosed.
+		select {
+		case <-p.done:
+			if err := p.closeConn(conn, closedClient, nil); err != nil {
+				log.Println("error while closing idle connection:", err)
+			}
+			return
+		default:
+		}
+
+		conn.idleSince = time.Now()
+		if err := p.putIdle(conn); err != nil {
+			log.Println("error while closing idle connection:", err)
+			return
+		}
+	}
+}
+
+// backgroundContext returns a context that is cancelled when the client is
+// closed.
+func (p *Client) backgroundContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-p.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
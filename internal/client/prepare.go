@@ -0,0 +1,302 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/edgedb/edgedb-go/internal/codecs"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+// PreparedQuery is a query that has been parsed by the server and whose
+// codecs have been built for a Go type. Running a prepared query skips
+// parsing and codec cache lookups. Use Client.Prepare() to get a
+// PreparedQuery. It is safe for concurrent use.
+type PreparedQuery struct {
+	client  *Client
+	cmd     string
+	outType reflect.Type
+	desc    *QueryDescription
+
+	// mu guards cdcs and executeCdcs which are replaced
+	// when the server describes the query again.
+	mu sync.Mutex
+
+	// cdcs are the codecs used by Query() and QuerySingle().
+	cdcs *codecPair
+
+	// executeCdcs are the codecs used by Execute().
+	// Execute() uses the Null output format which has no output type.
+	executeCdcs *codecPair
+}
+
+// Prepare parses cmd and builds codecs for decoding its results into values
+// of the same type as outSample. An error is returned if outSample's type
+// does not match the query's result type. outSample can be nil if the query
+// will only be run with PreparedQuery.Execute().
+//
+// The prepared query uses the client's configuration at the time Prepare is
+// called. Prepared queries require EdgeDB 5.0 or newer.
+func (p *Client) Prepare(
+	ctx context.Context,
+	cmd string,
+	outSample interface{},
+) (*PreparedQuery, error) {
+	method := "Execute"
+	var out interface{}
+	if outSample != nil {
+		method = "Query"
+		typ := reflect.SliceOf(reflect.TypeOf(outSample))
+		out = reflect.New(typ).Interface()
+	}
+
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := newQuery(
+		method,
+		cmd,
+		nil,
+		conn.capabilities1pX(),
+		copyState(p.state),
+		out,
+		true,
+		p.warningHandler,
+		p.serverLogHandler,
+		p.compilation,
+	)
+	if err != nil {
+		return nil, firstError(err, p.release(conn, nil))
+	}
+
+	desc, cdcs, err := conn.prepare(ctx, q)
+	if err = firstError(err, p.release(conn, err)); err != nil {
+		return nil, err
+	}
+
+	return &PreparedQuery{
+		client:      p,
+		cmd:         cmd,
+		outType:     q.outType,
+		desc:        desc,
+		cdcs:        cdcs,
+		executeCdcs: &codecPair{in: cdcs.in, out: codecs.NoOpDecoder},
+	}, nil
+}
+
+// Cardinality returns the cardinality of the query's result.
+func (p *PreparedQuery) Cardinality() Cardinality {
+	return p.desc.Cardinality
+}
+
+// Describe returns a description of the query's arguments and results.
+func (p *PreparedQuery) Describe() *QueryDescription {
+	return p.desc
+}
+
+// InputTypeID returns the id of the query's input type descriptor.
+func (p *PreparedQuery) InputTypeID() types.UUID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cdcs.in.DescriptorID()
+}
+
+// OutputTypeID returns the id of the query's output type descriptor.
+func (p *PreparedQuery) OutputTypeID() types.UUID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cdcs.out.DescriptorID()
+}
+
+// Execute runs the prepared query and discards its results.
+func (p *PreparedQuery) Execute(
+	ctx context.Context,
+	args ...interface{},
+) error {
+	return p.run(ctx, "Execute", nil, args)
+}
+
+// Query runs the prepared query and decodes its results into out.
+// out must be a pointer to a slice of the prepared out type.
+func (p *PreparedQuery) Query(
+	ctx context.Context,
+	out interface{},
+	args ...interface{},
+) error {
+	return p.run(ctx, "Query", out, args)
+}
+
+// QuerySingle runs the prepared query and decodes its result into out.
+// out must be a pointer to the prepared out type. If the query executes
+// successfully but doesn't return a result a NoDataError is returned. If the
+// out argument is an optional type the out argument will be set to missing
+// instead of returning a NoDataError.
+func (p *PreparedQuery) QuerySingle(
+	ctx context.Context,
+	out interface{},
+	args ...interface{},
+) error {
+	return p.run(ctx, "QuerySingle", out, args)
+}
+
+func (p *PreparedQuery) run(
+	ctx context.Context,
+	method string,
+	out interface{},
+	args []interface{},
+) error {
+	if method != "Execute" && p.outType == nil {
+		return &interfaceError{msg: fmt.Sprintf(
+			"%v can not be used with a query prepared without an out type",
+			method)}
+	}
+
+	conn, err := p.client.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	q, err := newQuery(
+		method,
+		p.cmd,
+		args,
+		conn.capabilities1pX(),
+		copyState(p.client.state),
+		out,
+		true,
+		p.client.warningHandler,
+		p.client.serverLogHandler,
+		p.client.compilation,
+	)
+	if err != nil {
+		return firstError(err, p.client.release(conn, nil))
+	}
+
+	if method != "Execute" && q.outType != p.outType {
+		err = &invalidArgumentError{msg: fmt.Sprintf(
+			"the \"out\" argument type %v does not match "+
+				"the prepared type %v", q.outType, p.outType)}
+		return firstError(err, p.client.release(conn, nil))
+	}
+
+	cdcs := p.codecs(method)
+	q.prepared = cdcs
+	err = conn.granularFlow(ctx, q)
+	if q.prepared != cdcs {
+		p.setCodecs(method, q.prepared)
+	}
+
+	err = queryResult(q, out, err)
+	return firstError(err, p.client.release(conn, err))
+}
+
+// codecs returns the codecs for running the query with method.
+func (p *PreparedQuery) codecs(method string) *codecPair {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if method == "Execute" {
+		return p.executeCdcs
+	}
+
+	return p.cdcs
+}
+
+// setCodecs replaces the codecs for running the query with method
+// after the server described the query again.
+func (p *PreparedQuery) setCodecs(method string, cdcs *codecPair) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if method == "Execute" {
+		p.executeCdcs = cdcs
+	} else {
+		p.cdcs = cdcs
+	}
+}
+
+func (c *borrowableConn) prepare(
+	ctx context.Context,
+	q *query,
+) (*QueryDescription, *codecPair, error) {
+	if e := c.assertUnborrowed(); e != nil {
+		return nil, nil, e
+	}
+
+	return c.conn.prepare(ctx, q)
+}
+
+func (c *reconnectingConn) prepare(
+	ctx context.Context,
+	q *query,
+) (*QueryDescription, *codecPair, error) {
+	if e := c.ensureConnection(ctx); e != nil {
+		return nil, nil, e
+	}
+
+	return c.borrowableConn.prepare(ctx, q)
+}
+
+// prepare parses q and builds its codecs.
+func (c *protocolConnection) prepare(
+	ctx context.Context,
+	q *query,
+) (*QueryDescription, *codecPair, error) {
+	if c.protocolVersion.LT(protocolVersion2p0) {
+		return nil, nil, &unsupportedFeatureError{
+			msg: "the server does not support prepared queries, " +
+				"upgrade to 5.0 or newer",
+		}
+	}
+
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	restore := c.useServerLogHandler(q.serverLogHandler)
+	stop := c.cancelOnDone(ctx)
+	d, err := c.parse2pX(r, q)
+	stop()
+	restore()
+
+	var (
+		desc *QueryDescription
+		cdcs *codecPair
+	)
+	if err == nil {
+		desc, err = describeV2(d)
+	}
+	if err == nil {
+		cdcs, err = c.codecsFromDescriptors2pX(q, d)
+	}
+
+	err = c.cancelledError(ctx, err)
+	return desc, cdcs, firstError(err, c.releaseReader(r))
+}
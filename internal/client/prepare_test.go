@@ -0,0 +1,112 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+func TestPrepare(t *testing.T) {
+	ctx := context.Background()
+
+	type Result struct {
+		A int64  `edgedb:"a"`
+		B string `edgedb:"b"`
+	}
+
+	prepared, err := client.Prepare(
+		ctx,
+		"SELECT (a := <int64>$0 + x, b := <str>x) "+
+			"FOR x IN {0, 1, 2}",
+		Result{},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, Many, prepared.Cardinality())
+	assert.NotEqual(t, types.UUID{}, prepared.InputTypeID())
+	assert.NotEqual(t, types.UUID{}, prepared.OutputTypeID())
+
+	var results []Result
+	err = prepared.Query(ctx, &results, int64(10))
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{10, "0"}, {11, "1"}, {12, "2"}}, results)
+
+	// The prepared query can be run again with different arguments.
+	err = prepared.Query(ctx, &results, int64(20))
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{20, "0"}, {21, "1"}, {22, "2"}}, results)
+
+	// Execute uses the Null output format instead of the prepared codecs.
+	require.NoError(t, prepared.Execute(ctx, int64(10)))
+	err = prepared.Query(ctx, &results, int64(30))
+	require.NoError(t, err)
+	assert.Equal(t, []Result{{30, "0"}, {31, "1"}, {32, "2"}}, results)
+
+	var wrongType []int64
+	err = prepared.Query(ctx, &wrongType, int64(10))
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"the \"out\" argument type int64 does not match "+
+		"the prepared type edgedb.Result")
+}
+
+func TestPrepareSingle(t *testing.T) {
+	ctx := context.Background()
+
+	prepared, err := client.Prepare(
+		ctx,
+		"SELECT <str>$0 IF <bool>$1 ELSE <str>{}",
+		types.OptionalStr{},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, AtMostOne, prepared.Cardinality())
+
+	var result types.OptionalStr
+	err = prepared.QuerySingle(ctx, &result, "hello", true)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewOptionalStr("hello"), result)
+
+	err = prepared.QuerySingle(ctx, &result, "hello", false)
+	require.NoError(t, err)
+	assert.Equal(t, types.OptionalStr{}, result)
+}
+
+func TestPrepareMismatchedType(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := client.Prepare(ctx, "SELECT <str>$0", int64(0))
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"the \"out\" argument does not match query schema: "+
+		"expected int64 to be string or edgedb.OptionalStr got int64")
+}
+
+func TestPrepareExecute(t *testing.T) {
+	ctx := context.Background()
+
+	prepared, err := client.Prepare(ctx, "SELECT <int64>$0", nil)
+	require.NoError(t, err)
+	require.NoError(t, prepared.Execute(ctx, int64(1)))
+
+	var result []int64
+	err = prepared.Query(ctx, &result, int64(1))
+	assert.EqualError(t, err, "edgedb.InterfaceError: "+
+		"Query can not be used with a query prepared without an out type")
+}
@@ -0,0 +1,88 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ConnectionCredentials are the credentials used to authenticate a new
+// connection. Empty fields are replaced with the values resolved from
+// Options, the DSN, environment variables etc.
+type ConnectionCredentials struct {
+	User      string
+	Password  string
+	SecretKey string
+}
+
+// CredentialsProvider supplies credentials for new connections so that
+// rotated secrets are picked up without recreating the client. It must be
+// safe for concurrent use.
+type CredentialsProvider interface {
+	// Credentials is called before each new connection is opened.
+	// If the server rejects the credentials with an AuthenticationError,
+	// Credentials is called again with stale set to true and the
+	// connection is retried once.
+	Credentials(ctx context.Context, stale bool) (
+		ConnectionCredentials, error)
+}
+
+// withProvidedCredentials returns a copy of cfg with the credentials from
+// cfg.credentialsProvider. cfg is returned unchanged if it has no provider.
+func (c *connConfig) withProvidedCredentials(
+	ctx context.Context,
+	stale bool,
+) (*connConfig, error) {
+	if c.credentialsProvider == nil {
+		return c, nil
+	}
+
+	creds, err := c.credentialsProvider.Credentials(ctx, stale)
+	if err != nil {
+		var edbErr Error
+		if errors.As(err, &edbErr) {
+			return nil, err
+		}
+
+		return nil, &clientConnectionFailedError{
+			err: fmt.Errorf("cannot get credentials: %w", err),
+		}
+	}
+
+	cfg := *c
+	if creds.User != "" {
+		cfg.user = creds.User
+	}
+
+	if creds.Password != "" {
+		cfg.password = creds.Password
+	}
+
+	if creds.SecretKey != "" {
+		cfg.secretKey = creds.SecretKey
+	}
+
+	return &cfg, nil
+}
+
+// isAuthenticationError returns true if the server rejected the credentials.
+func isAuthenticationError(err error) bool {
+	var edbErr Error
+	return errors.As(err, &edbErr) && edbErr.Category(AuthenticationError)
+}
@@ -0,0 +1,448 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// proxyFromEnvironment returns the proxy that should be used to connect to
+// address. HTTPS_PROXY is used if it is set, otherwise ALL_PROXY is used.
+// Addresses matching NO_PROXY and loopback addresses are connected to
+// directly. The lowercase variants of the environment variables are also
+// accepted. A nil URL means no proxy should be used.
+func proxyFromEnvironment(address string) (*url.URL, error) {
+	name, raw := getProxyEnv("HTTPS_PROXY", "https_proxy")
+	if raw == "" {
+		name, raw = getProxyEnv("ALL_PROXY", "all_proxy")
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	_, noProxy := getProxyEnv("NO_PROXY", "no_proxy")
+	if !useProxy(host, port, noProxy) {
+		return nil, nil
+	}
+
+	proxy, err := parseProxyURL(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	return proxy, nil
+}
+
+func getProxyEnv(names ...string) (string, string) {
+	for _, name := range names {
+		if val, ok := os.LookupEnv(name); ok && val != "" {
+			return name, val
+		}
+	}
+
+	return "", ""
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf(
+			"unsupported proxy scheme %q, expected "+
+				"http, https, socks5 or socks5h", proxy.Scheme)
+	}
+
+	if proxy.Hostname() == "" {
+		return nil, fmt.Errorf("missing proxy host in %q", raw)
+	}
+
+	return proxy, nil
+}
+
+// useProxy returns false if host:port matches an entry in noProxy, a comma
+// separated list of host names, domain suffixes, IP addresses and CIDR
+// ranges each with an optional port, or if host is a loopback address.
+func useProxy(host, port, noProxy string) bool {
+	if host == "localhost" {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return false
+		}
+
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return false
+			}
+			continue
+		}
+
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+
+		if ip != nil {
+			if ip.Equal(net.ParseIP(entry)) {
+				return false
+			}
+			continue
+		}
+
+		host = strings.ToLower(host)
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialProxy connects to address through proxy using dial to connect to the
+// proxy server.
+func dialProxy(
+	ctx context.Context,
+	dial DialFunc,
+	proxy *url.URL,
+	address string,
+) (net.Conn, error) {
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxy.Hostname(), defaultProxyPort(proxy))
+	}
+
+	conn, err := dial(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxy.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	// The handshake with the proxy must respect the context deadline.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	switch proxy.Scheme {
+	case "socks5", "socks5h":
+		conn, err = socks5Connect(ctx, conn, proxy, address)
+	default:
+		conn, err = httpConnect(conn, proxy, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func defaultProxyPort(proxy *url.URL) string {
+	switch proxy.Scheme {
+	case "https":
+		return "443"
+	case "socks5", "socks5h":
+		return "1080"
+	default:
+		return "80"
+	}
+}
+
+// httpConnect establishes a tunnel to address with an HTTP CONNECT request.
+func httpConnect(
+	conn net.Conn,
+	proxy *url.URL,
+	address string,
+) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		req.SetBasicAuth(proxy.User.Username(), password)
+		req.Header.Set("Proxy-Authorization", req.Header.Get("Authorization"))
+		req.Header.Del("Authorization")
+	}
+
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, &clientConnectionFailedError{msg: fmt.Sprintf(
+			"proxy %v refused to connect to %v: %v",
+			proxy.Host, address, resp.Status)}
+	}
+
+	if r.Buffered() > 0 {
+		// Don't lose data that was read along with the response.
+		return &bufferedConn{Conn: conn, r: r}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn with a read buffer.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+var socks5Errors = map[byte]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// socks5Connect establishes a tunnel to address with the SOCKS5 protocol
+// described in RFC 1928 and RFC 1929. Host names are resolved by the proxy
+// when the scheme is socks5h and locally otherwise.
+func socks5Connect(
+	ctx context.Context,
+	conn net.Conn,
+	proxy *url.URL,
+	address string,
+) (net.Conn, error) {
+	fail := func(err error) (net.Conn, error) {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return fail(err)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fail(err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil && proxy.Scheme == "socks5" {
+		ips, e := net.DefaultResolver.LookupIP(ctx, "ip", host)
+		if e != nil {
+			return fail(e)
+		}
+		ip = ips[0]
+	}
+
+	// greeting: version, number of methods, methods
+	methods := []byte{0x00} // no authentication
+	if proxy.User != nil {
+		methods = append(methods, 0x02) // username/password
+	}
+
+	msg := append([]byte{0x05, byte(len(methods))}, methods...)
+	if _, err = conn.Write(msg); err != nil {
+		return fail(err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return fail(err)
+	}
+
+	if reply[0] != 0x05 {
+		return fail(fmt.Errorf(
+			"unexpected SOCKS version %v from proxy %v", reply[0], proxy.Host))
+	}
+
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if proxy.User == nil {
+			return fail(errors.New("SOCKS proxy requires authentication"))
+		}
+
+		if err = socks5Authenticate(conn, proxy.User); err != nil {
+			return fail(err)
+		}
+	default:
+		return fail(&clientConnectionFailedError{msg: fmt.Sprintf(
+			"no acceptable SOCKS authentication method for proxy %v",
+			proxy.Host)})
+	}
+
+	// request: version, CONNECT, reserved, address type, address, port
+	msg = []byte{0x05, 0x01, 0x00}
+	switch {
+	case ip.To4() != nil:
+		msg = append(msg, 0x01)
+		msg = append(msg, ip.To4()...)
+	case ip != nil:
+		msg = append(msg, 0x04)
+		msg = append(msg, ip.To16()...)
+	default:
+		if len(host) > 255 {
+			return fail(fmt.Errorf("host name too long: %q", host))
+		}
+		msg = append(msg, 0x03, byte(len(host)))
+		msg = append(msg, host...)
+	}
+	msg = append(msg, byte(port>>8), byte(port))
+
+	if _, err = conn.Write(msg); err != nil {
+		return fail(err)
+	}
+
+	// reply: version, status, reserved, address type
+	reply = make([]byte, 4)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return fail(err)
+	}
+
+	if reply[1] != 0x00 {
+		reason, ok := socks5Errors[reply[1]]
+		if !ok {
+			reason = fmt.Sprintf("unknown error %v", reply[1])
+		}
+
+		return fail(&clientConnectionFailedError{msg: fmt.Sprintf(
+			"proxy %v refused to connect to %v: %v",
+			proxy.Host, address, reason)})
+	}
+
+	// Skip the bound address and port.
+	var skip int
+	switch reply[3] {
+	case 0x01:
+		skip = net.IPv4len + 2
+	case 0x04:
+		skip = net.IPv6len + 2
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err = io.ReadFull(conn, length); err != nil {
+			return fail(err)
+		}
+		skip = int(length[0]) + 2
+	default:
+		return fail(fmt.Errorf(
+			"unexpected SOCKS address type %v", reply[3]))
+	}
+
+	if _, err = io.ReadFull(conn, make([]byte, skip)); err != nil {
+		return fail(err)
+	}
+
+	return conn, nil
+}
+
+func socks5Authenticate(conn net.Conn, user *url.Userinfo) error {
+	username := user.Username()
+	password, _ := user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("SOCKS username and password " +
+			"must not be longer than 255 bytes")
+	}
+
+	msg := []byte{0x01, byte(len(username))}
+	msg = append(msg, username...)
+	msg = append(msg, byte(len(password)))
+	msg = append(msg, password...)
+	if _, err := conn.Write(msg); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+
+	if reply[1] != 0x00 {
+		return &clientConnectionFailedError{
+			msg: "SOCKS proxy authentication failed",
+		}
+	}
+
+	return nil
+}
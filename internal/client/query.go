@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
 	"github.com/edgedb/edgedb-go/internal/header"
@@ -34,18 +35,25 @@ import (
 type WarningHandler = func([]error) error
 
 type query struct {
-	out            reflect.Value
-	outType        reflect.Type
-	method         string
-	lang           Language
-	cmd            string
-	fmt            Format
-	expCard        Cardinality
-	args           []interface{}
-	capabilities   uint64
-	state          map[string]interface{}
-	parse          bool
-	warningHandler WarningHandler
+	out              reflect.Value
+	outType          reflect.Type
+	method           string
+	lang             Language
+	cmd              string
+	fmt              Format
+	expCard          Cardinality
+	args             []interface{}
+	capabilities     uint64
+	state            map[string]interface{}
+	parse            bool
+	warningHandler   WarningHandler
+	serverLogHandler ServerLogHandler
+	compilation      compilationOptions
+
+	// prepared are the codecs from a PreparedQuery.
+	// The codec caches are not used if prepared is set.
+	// prepared is replaced if the server describes the query again.
+	prepared *codecPair
 }
 
 func (q *query) flat() bool {
@@ -67,6 +75,60 @@ func (q *query) headers0pX() header.Header0pX {
 	return header.Header0pX{header.AllowCapabilities: bts}
 }
 
+// Compilation flags sent in Parse and Execute messages
+// for protocol version 1.0 and later.
+const (
+	injectOutputTypeIDs   uint64 = 1 << 0
+	injectOutputTypeNames uint64 = 1 << 1
+	injectOutputObjectIDs uint64 = 1 << 2
+)
+
+// compilationOptions control how the server compiles a query.
+// The zero value is the server's default behavior.
+type compilationOptions struct {
+	implicitLimit     uint64
+	implicitTypeIDs   bool
+	implicitTypeNames bool
+	implicitObjectIDs bool
+}
+
+func (o compilationOptions) flags() uint64 {
+	var flags uint64
+
+	if o.implicitTypeIDs {
+		flags |= injectOutputTypeIDs
+	}
+
+	if o.implicitTypeNames {
+		flags |= injectOutputTypeNames
+	}
+
+	if o.implicitObjectIDs {
+		flags |= injectOutputObjectIDs
+	}
+
+	return flags
+}
+
+func (o compilationOptions) addHeaders0pX(headers header.Header0pX) {
+	if o.implicitLimit > 0 {
+		headers[header.ImplicitLimit] = []byte(
+			strconv.FormatUint(o.implicitLimit, 10))
+	}
+
+	if o.implicitTypeIDs {
+		headers[header.ImplicitTypeIDs] = []byte("true")
+	}
+
+	if o.implicitTypeNames {
+		headers[header.ImplicitTypeNames] = []byte("true")
+	}
+
+	if !o.implicitObjectIDs {
+		headers[header.ExplicitObjectIDs] = []byte("true")
+	}
+}
+
 // newQuery returns a new granular flow query.
 func newQuery(
 	method, cmd string,
@@ -76,6 +138,8 @@ func newQuery(
 	out interface{},
 	parse bool,
 	warningHandler WarningHandler,
+	serverLogHandler ServerLogHandler,
+	compilation compilationOptions,
 ) (*query, error) {
 	var (
 		expCard Cardinality
@@ -90,16 +154,38 @@ func newQuery(
 			lang = SQL
 		}
 		return &query{
-			method:         method,
-			lang:           lang,
-			cmd:            cmd,
-			fmt:            Null,
-			expCard:        Many,
-			args:           args,
-			capabilities:   capabilities,
-			state:          state,
-			parse:          parse,
-			warningHandler: warningHandler,
+			method:           method,
+			lang:             lang,
+			cmd:              cmd,
+			fmt:              Null,
+			expCard:          Many,
+			args:             args,
+			capabilities:     capabilities,
+			state:            state,
+			parse:            parse,
+			warningHandler:   warningHandler,
+			serverLogHandler: serverLogHandler,
+			compilation:      compilation,
+		}, nil
+	case "QueryIter", "QueryIterSQL":
+		if method == "QueryIterSQL" {
+			lang = SQL
+		}
+		// Rows are decoded by Rows.Scan,
+		// so there is no out value to introspect.
+		return &query{
+			method:           method,
+			lang:             lang,
+			cmd:              cmd,
+			fmt:              Binary,
+			expCard:          Many,
+			args:             args,
+			capabilities:     capabilities,
+			state:            state,
+			parse:            parse,
+			warningHandler:   warningHandler,
+			serverLogHandler: serverLogHandler,
+			compilation:      compilation,
 		}, nil
 	case "Query":
 		expCard = Many
@@ -122,16 +208,18 @@ func newQuery(
 	}
 
 	q := query{
-		method:         method,
-		lang:           lang,
-		cmd:            cmd,
-		fmt:            frmt,
-		expCard:        expCard,
-		args:           args,
-		capabilities:   capabilities,
-		state:          state,
-		parse:          parse,
-		warningHandler: warningHandler,
+		method:           method,
+		lang:             lang,
+		cmd:              cmd,
+		fmt:              frmt,
+		expCard:          expCard,
+		args:             args,
+		capabilities:     capabilities,
+		state:            state,
+		parse:            parse,
+		warningHandler:   warningHandler,
+		serverLogHandler: serverLogHandler,
+		compilation:      compilation,
 	}
 
 	var err error
@@ -174,6 +262,8 @@ func runQuery(
 	args []interface{},
 	state map[string]interface{},
 	warningHandler WarningHandler,
+	serverLogHandler ServerLogHandler,
+	compilation compilationOptions,
 ) error {
 	if method == "QuerySingleJSON" {
 		switch out.(type) {
@@ -194,13 +284,21 @@ func runQuery(
 		out,
 		true,
 		warningHandler,
+		serverLogHandler,
+		compilation,
 	)
 	if err != nil {
 		return err
 	}
 
 	err = c.granularFlow(ctx, q)
+	return queryResult(q, out, err)
+}
 
+// queryResult returns the error from running q. If a singleton query has no
+// result and out is an optional type, out is unset instead of returning
+// a NoDataError.
+func queryResult(q *query, out interface{}, err error) error {
 	var edbErr Error
 	if errors.As(err, &edbErr) &&
 		edbErr.Category(NoDataError) &&
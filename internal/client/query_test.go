@@ -217,6 +217,29 @@ func TestNamedQueryArguments(t *testing.T) {
 	assert.Equal(t, [][]int64{{5, 8}}, result)
 }
 
+func TestStructQueryArguments(t *testing.T) {
+	ctx := context.Background()
+
+	type Args struct {
+		First  int64               `edgedb:"first"`
+		Second types.OptionalInt64 `edgedb:"second"`
+	}
+
+	query := "SELECT [<int64>$first, <optional int64>$second ?? 0]"
+
+	var result [][]int64
+	err := client.Query(ctx, query, &result, Args{
+		First:  5,
+		Second: types.NewOptionalInt64(8),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]int64{{5, 8}}, result)
+
+	err = client.Query(ctx, query, &result, &Args{First: 5})
+	require.NoError(t, err)
+	assert.Equal(t, [][]int64{{5, 0}}, result)
+}
+
 func TestNumberedQueryArguments(t *testing.T) {
 	ctx := context.Background()
 	result := [][]int64{}
@@ -1179,3 +1202,88 @@ func TestWithWarningHandler(t *testing.T) {
 	require.NoError(t, err)
 	require.Greater(t, len(seen), 0)
 }
+
+func TestQueryIntoDynamicTypes(t *testing.T) {
+	ctx := context.Background()
+
+	var objects []map[string]interface{}
+	err := client.Query(
+		ctx,
+		"SELECT schema::Object { name } "+
+			"FILTER .name = 'std::str' LIMIT 1",
+		&objects,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(objects))
+	assert.Equal(t, "std::str", objects[0]["name"])
+	assert.IsType(t, types.UUID{}, objects[0]["id"])
+
+	var tuple []interface{}
+	err = client.QuerySingle(ctx, "SELECT (1, 'a')", &tuple)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(1), "a"}, tuple)
+
+	var result interface{}
+	err = client.QuerySingle(
+		ctx,
+		"SELECT (a := [1, 2], b := (c := <float64>1.5))",
+		&result,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a": []interface{}{int64(1), int64(2)},
+		"b": map[string]interface{}{"c": float64(1.5)},
+	}, result)
+
+	var many []interface{}
+	err = client.Query(ctx, "SELECT {'x', 'y'}", &many)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"x", "y"}, many)
+}
+
+func TestWithImplicitLimit(t *testing.T) {
+	ctx := context.Background()
+
+	var result []int64
+	err := client.WithImplicitLimit(2).Query(ctx, "SELECT {1, 2, 3}", &result)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, result)
+
+	// The limit is not shared with the parent client.
+	err = client.Query(ctx, "SELECT {1, 2, 3}", &result)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, result)
+}
+
+func TestWithImplicitTypeNames(t *testing.T) {
+	ctx := context.Background()
+
+	type Object struct {
+		TypeID   types.UUID `edgedb:"__tid__"`
+		TypeName string     `edgedb:"__tname__"`
+		ID       types.UUID `edgedb:"id"`
+		Name     string     `edgedb:"name"`
+	}
+
+	query := "SELECT schema::ObjectType { name } " +
+		"FILTER .name = 'schema::ObjectType' LIMIT 1"
+
+	var result Object
+	err := client.
+		WithImplicitTypeIDs(true).
+		WithImplicitTypeNames(true).
+		WithExplicitObjectIDs(false).
+		QuerySingle(ctx, query, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "schema::ObjectType", result.TypeName)
+	assert.Equal(t, result.ID, result.TypeID)
+
+	var id types.UUID
+	err = client.QuerySingle(
+		ctx,
+		"SELECT (INTROSPECT schema::ObjectType).id",
+		&id,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, id, result.TypeID)
+}
@@ -19,6 +19,8 @@ package edgedb
 import (
 	"context"
 	"errors"
+	"io"
+	"sync"
 	"time"
 )
 
@@ -27,8 +29,17 @@ type reconnectingConn struct {
 	cacheCollection
 	cfg *connConfig
 
+	// hosts is shared by all of a client's connections.
+	// It is nil if the client has a single host.
+	hosts *hostList
+
 	// isClosed is true when the connection has been closed by a user.
 	isClosed bool
+
+	// mu guards conn and forceClosed
+	// which are accessed concurrently by forceClose.
+	mu          sync.Mutex
+	forceClosed bool
 }
 
 // reconnect establishes a new connection with the server retrying the
@@ -47,13 +58,43 @@ func (c *reconnectingConn) reconnect(
 		maxTime = deadline
 	}
 
-	var edbErr Error
+	var (
+		edbErr  Error
+		stale   bool
+		retried bool
+	)
 	for {
-		conn, err := connectWithTimeout(ctx, c.cfg, c.cacheCollection)
+		cfg, err := c.cfg.withProvidedCredentials(ctx, stale)
+		if err != nil {
+			return err
+		}
+		stale = false
+
+		conn, err := c.connect(ctx, cfg)
 		if err == nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if c.forceClosed {
+				return wrapAll(
+					&interfaceError{msg: "client closed"},
+					conn.close(),
+				)
+			}
+
 			c.conn = conn
 			return nil
 		}
+
+		// Retry once with fresh credentials if they were rejected.
+		if !retried &&
+			cfg.credentialsProvider != nil &&
+			isAuthenticationError(err) {
+			stale = true
+			retried = true
+			continue
+		}
+
 		if single ||
 			errors.Is(err, context.Canceled) ||
 			errors.Is(err, context.DeadlineExceeded) ||
@@ -68,6 +109,44 @@ func (c *reconnectingConn) reconnect(
 	}
 }
 
+// connect connects to the first of the client's hosts that is available.
+func (c *reconnectingConn) connect(
+	ctx context.Context,
+	cfg *connConfig,
+) (*protocolConnection, error) {
+	if c.hosts == nil {
+		return connectWithTimeout(ctx, cfg, c.cacheCollection)
+	}
+
+	var (
+		edbErr Error
+		err    error
+	)
+	for _, addr := range c.hosts.candidates() {
+		hostCfg, e := cfg.withAddr(addr)
+		if e != nil {
+			return nil, e
+		}
+
+		var conn *protocolConnection
+		conn, err = connectWithTimeout(ctx, hostCfg, c.cacheCollection)
+		if err == nil {
+			c.hosts.succeeded(addr)
+			return conn, nil
+		}
+
+		if ctx.Err() != nil ||
+			!errors.As(err, &edbErr) ||
+			!edbErr.Category(ClientConnectionError) {
+			return nil, err
+		}
+
+		c.hosts.failed(addr)
+	}
+
+	return nil, err
+}
+
 // ensureConnection reconnects to the server if not connected.
 func (c *reconnectingConn) ensureConnection(ctx context.Context) error {
 	if c.conn != nil && !c.conn.isClosed() && !c.isClosed {
@@ -96,6 +175,48 @@ func (c *reconnectingConn) granularFlow(
 	return c.borrowableConn.granularFlow(ctx, q)
 }
 
+func (c *reconnectingConn) queryIter(
+	ctx context.Context,
+	q *query,
+) (*Rows, error) {
+	if e := c.ensureConnection(ctx); e != nil {
+		return nil, e
+	}
+
+	return c.borrowableConn.queryIter(ctx, q)
+}
+
+func (c *reconnectingConn) dump(ctx context.Context, out io.Writer) error {
+	if e := c.ensureConnection(ctx); e != nil {
+		return e
+	}
+
+	return c.borrowableConn.dump(ctx, out)
+}
+
+func (c *reconnectingConn) restore(ctx context.Context, in io.Reader) error {
+	if e := c.ensureConnection(ctx); e != nil {
+		return e
+	}
+
+	return c.borrowableConn.restore(ctx, in)
+}
+
+// forceClose closes the connection's socket without waiting for the query
+// that is running on it. It is safe to call from other goroutines.
+// The connection will not reconnect after it is force closed.
+func (c *reconnectingConn) forceClose() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.forceClosed = true
+	if c.conn == nil || c.conn.isClosed() {
+		return nil
+	}
+
+	return c.conn.soc.Close()
+}
+
 // Close closes the connection. Connections are not usable after they are
 // closed.
 func (c *reconnectingConn) Close() (err error) {
@@ -0,0 +1,204 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSource = "default"
+	redacted      = "<redacted>"
+	pemData       = "<PEM data>"
+	callback      = "<callback>"
+)
+
+// ResolvedValue is a connection parameter and where it came from.
+type ResolvedValue struct {
+	// Value is the parameter's value. Passwords and secret keys are
+	// redacted and certificates are replaced with a placeholder.
+	// Value is empty if the parameter is not set.
+	Value string
+
+	// Source describes where the value came from, for example
+	// "EDGEDB_HOST environment variable". It is "default" if the value was
+	// not set explicitly.
+	Source string
+}
+
+func (v ResolvedValue) String() string {
+	return fmt.Sprintf("%v (from %v)", v.Value, v.Source)
+}
+
+// ResolvedConfig is the connection configuration that a client created with
+// the same DSN and Options would use. It is meant for logging and
+// troubleshooting and does not contain secrets.
+type ResolvedConfig struct {
+	Host               ResolvedValue
+	Port               ResolvedValue
+	HostOrder          ResolvedValue
+	ReadReplicas       ResolvedValue
+	Branch             ResolvedValue
+	User               ResolvedValue
+	Password           ResolvedValue
+	SecretKey          ResolvedValue
+	TLSSecurity        ResolvedValue
+	TLSServerName      ResolvedValue
+	TLSCA              ResolvedValue
+	TLSClientCert      ResolvedValue
+	WaitUntilAvailable ResolvedValue
+}
+
+// String returns the resolved configuration with one parameter per line.
+func (c *ResolvedConfig) String() string {
+	params := []struct {
+		name  string
+		value ResolvedValue
+	}{
+		{"host", c.Host},
+		{"port", c.Port},
+		{"host_order", c.HostOrder},
+		{"read_replicas", c.ReadReplicas},
+		{"branch", c.Branch},
+		{"user", c.User},
+		{"password", c.Password},
+		{"secret_key", c.SecretKey},
+		{"tls_security", c.TLSSecurity},
+		{"tls_server_name", c.TLSServerName},
+		{"tls_ca", c.TLSCA},
+		{"tls_client_cert", c.TLSClientCert},
+		{"wait_until_available", c.WaitUntilAvailable},
+	}
+
+	var b strings.Builder
+	for _, p := range params {
+		fmt.Fprintf(&b, "%v: %v\n", p.name, p.value)
+	}
+
+	return b.String()
+}
+
+// ResolveConfig resolves the connection configuration from dsn, opts,
+// environment variables, credentials files and the project's edgedb.toml
+// the same way CreateClientDSN does, and reports the source of each
+// parameter. It does not connect to the server.
+func ResolveConfig(dsn string, opts Options) (*ResolvedConfig, error) { // nolint:gocritic,lll
+	resolver, err := newConfigResolver(dsn, &opts, newCfgPaths())
+	if err != nil {
+		return nil, &configurationError{err: err}
+	}
+
+	cfg, err := resolver.config(&opts)
+	if err != nil {
+		return nil, &configurationError{err: err}
+	}
+
+	return resolver.resolved(cfg), nil
+}
+
+// resolvedValue returns val with the source of v or the default source if v
+// was not set.
+func resolvedValue(v cfgVal, val string) ResolvedValue {
+	if v.val == nil {
+		return ResolvedValue{Value: val, Source: defaultSource}
+	}
+
+	return ResolvedValue{Value: val, Source: v.source}
+}
+
+// redactedValue hides the value of v if it is set.
+func redactedValue(v cfgVal, placeholder string) ResolvedValue {
+	if v.val == nil {
+		return ResolvedValue{Source: defaultSource}
+	}
+
+	return ResolvedValue{Value: placeholder, Source: v.source}
+}
+
+func (r *configResolver) resolved(cfg *connConfig) *ResolvedConfig {
+	host := "localhost"
+	if r.host.val != nil {
+		host = r.host.val.(string)
+	}
+
+	port := 5656
+	if r.port.val != nil {
+		port = r.port.val.(int)
+	}
+
+	hostOrder := HostOrderInOrder
+	if cfg.hostOrder != "" {
+		hostOrder = cfg.hostOrder
+	}
+
+	var replicas string
+	if r.readReplicas.val != nil {
+		replicas = r.readReplicas.val.(string)
+	}
+
+	password := redactedValue(r.password, redacted)
+	if cfg.password == "" {
+		password.Value = ""
+	}
+
+	clientCert := redactedValue(r.tlsClientCert, pemData)
+	if _, ok := r.tlsClientCert.val.(getClientCertFunc); ok {
+		clientCert.Value = callback
+	}
+
+	wait := resolvedValue(
+		r.waitUntilAvailable, cfg.waitUntilAvailable.String())
+
+	return &ResolvedConfig{
+		Host:               resolvedValue(r.host, host),
+		Port:               resolvedValue(r.port, strconv.Itoa(port)),
+		HostOrder:          resolvedValue(r.hostOrder, string(hostOrder)),
+		ReadReplicas:       resolvedValue(r.readReplicas, replicas),
+		Branch:             resolvedValue(r.database, cfg.branch),
+		User:               resolvedValue(r.user, cfg.user),
+		Password:           password,
+		SecretKey:          redactedValue(r.secretKey, redacted),
+		TLSSecurity:        r.resolvedTLSSecurity(cfg),
+		TLSServerName:      resolvedValue(r.tlsServerName, cfg.tlsServerName),
+		TLSCA:              redactedValue(r.tlsCAData, pemData),
+		TLSClientCert:      clientCert,
+		WaitUntilAvailable: wait,
+	}
+}
+
+// resolvedTLSSecurity reports EDGEDB_CLIENT_SECURITY as the source if it
+// changed the TLS security mode.
+func (r *configResolver) resolvedTLSSecurity(
+	cfg *connConfig,
+) ResolvedValue {
+	if r.tlsSecurity.val != nil && r.tlsSecurity.val == cfg.tlsSecurity {
+		return resolvedValue(r.tlsSecurity, cfg.tlsSecurity)
+	}
+
+	switch os.Getenv("EDGEDB_CLIENT_SECURITY") {
+	case "insecure_dev_mode", "strict":
+		return ResolvedValue{
+			Value:  cfg.tlsSecurity,
+			Source: "EDGEDB_CLIENT_SECURITY environment variable",
+		}
+	default:
+		return resolvedValue(r.tlsSecurity, cfg.tlsSecurity)
+	}
+}
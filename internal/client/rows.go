@@ -0,0 +1,326 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/codecs"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	"github.com/edgedb/edgedb-go/internal/introspect"
+)
+
+// Rows is an iterator over the results of a query started with QueryIter().
+// Results are decoded one at a time as they are received from the server
+// instead of being collected into a slice, so large results can be processed
+// without holding all of them in memory.
+//
+// Rows holds a connection until it has been iterated to the end or closed.
+// Always call Close() after a successful QueryIter() call.
+//
+//	rows, err := client.QueryIter(ctx, "SELECT User { name }")
+//	if err != nil {
+//	    return err
+//	}
+//	defer rows.Close()
+//
+//	for rows.Next() {
+//	    var user User
+//	    if err := rows.Scan(&user); err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+//
+//	return rows.Err()
+type Rows struct {
+	conn   *protocolConnection
+	reader *buff.Reader
+	done   *buff.DoneReadingSignal
+	q      *query
+	desc   descriptor.V2
+
+	row    []byte
+	hasRow bool
+
+	err      error
+	finished bool
+
+	// ctx is the context passed to QueryIter().
+	ctx context.Context
+
+	// stop stops ctx from cancelling the query.
+	stop func()
+
+	// restore restores the connection's server log handler.
+	restore func()
+
+	// release is called once after the last message
+	// for the query has been read.
+	release func(error) error
+}
+
+// Next advances to the next result.
+// It returns false when there are no more results or an error occurred.
+// Err() should be checked after Next() returns false.
+func (r *Rows) Next() bool {
+	if r.finished {
+		return false
+	}
+
+	r.row = nil
+	r.hasRow = false
+
+	for r.reader.Next(r.done.Chan) {
+		switch Message(r.reader.MsgType) {
+		case StateDataDescription:
+			if e := r.conn.decodeStateDataDescription(r.reader); e != nil {
+				r.err = wrapAll(r.err, e)
+			}
+		case CommandDataDescription:
+			descs, e := r.conn.decodeCommandDataDescriptionMsg2pX(
+				r.reader,
+				r.q,
+			)
+			if e != nil {
+				r.err = wrapAll(r.err, e)
+			} else {
+				r.desc = descs.Out
+			}
+		case Data:
+			elmCount := r.reader.PopUint16()
+			if elmCount != 1 {
+				r.reader.DiscardMessage()
+				r.err = wrapAll(r.err, fmt.Errorf(
+					"unexpected number of elements: expected 1, got %v",
+					elmCount,
+				))
+				continue
+			}
+
+			r.row = r.reader.PopSlice(r.reader.PopUint32()).Buf
+			r.hasRow = true
+			return true
+		case CommandComplete:
+			e := r.conn.decodeCommandCompleteMsg2pX(r.q, r.reader)
+			if e != nil {
+				r.err = wrapAll(r.err, e)
+			}
+		case ReadyForCommand:
+			decodeReadyForCommandMsg(r.reader)
+			r.done.Signal()
+		case ErrorResponse:
+			r.err = wrapAll(r.err, decodeErrorResponseMsg(r.reader, r.q.cmd))
+		default:
+			if e := r.conn.fallThrough(r.reader); e != nil {
+				// the connection will not be usable after this x_x
+				r.err = wrapAll(r.err, e)
+				r.finish()
+				return false
+			}
+		}
+	}
+
+	if r.reader.Err != nil {
+		r.err = wrapAll(r.err, r.reader.Err)
+	}
+
+	r.finish()
+	return false
+}
+
+// finish hands the reader back to the connection
+// and releases the connection.
+func (r *Rows) finish() {
+	if r.finished {
+		return
+	}
+
+	r.finished = true
+	r.row = nil
+	r.hasRow = false
+
+	r.stop()
+	r.restore()
+	r.err = r.conn.cancelledError(r.ctx, r.err)
+
+	err := firstError(r.err, r.conn.releaseReader(r.reader))
+	r.err = firstError(err, r.release(err))
+}
+
+// Scan decodes the current result into out.
+// out must be a pointer to a value that matches the query's result type
+// as it would for an element of the slice passed to Query().
+func (r *Rows) Scan(out interface{}) error {
+	if !r.hasRow {
+		return &interfaceError{msg: "Scan called without calling Next"}
+	}
+
+	val, err := introspect.ValueOf(out)
+	if err != nil {
+		return &interfaceError{err: err}
+	}
+
+	decoder, err := r.decoder(val.Type())
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(
+		buff.SimpleReader(r.row),
+		unsafe.Pointer(val.UnsafeAddr()),
+	)
+}
+
+func (r *Rows) decoder(typ reflect.Type) (codecs.Decoder, error) {
+	key := codecKey{ID: r.desc.ID, Type: typ}
+	if decoder, ok := r.conn.outCodecCache.Get(key); ok {
+		return decoder.(codecs.Decoder), nil
+	}
+
+	decoder, err := codecs.BuildDecoderV2(
+		&r.desc,
+		typ,
+		codecs.Path(typ.String()),
+		r.conn.customScalars,
+	)
+	if err != nil {
+		return nil, &invalidArgumentError{msg: fmt.Sprintf(
+			"the \"out\" argument does not match query schema: %v", err)}
+	}
+
+	r.conn.outCodecCache.Put(key, decoder)
+	return decoder, nil
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Close discards any remaining results and releases the connection.
+// Close is safe to call more than once.
+// It returns the same error as Err().
+func (r *Rows) Close() error {
+	for r.Next() {
+		// discard remaining results
+		// so that the connection is left in a usable state.
+	}
+
+	return r.err
+}
+
+// queryIter sends q to the server and returns Rows
+// for reading the results.
+func (c *protocolConnection) queryIter(
+	ctx context.Context,
+	q *query,
+) (*Rows, error) {
+	if c.protocolVersion.LT(protocolVersion2p0) {
+		return nil, &unsupportedFeatureError{
+			msg: "the server does not support iterating over results, " +
+				"upgrade to 5.0 or newer",
+		}
+	}
+
+	r, err := c.acquireReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	err = c.soc.SetDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := c.useServerLogHandler(q.serverLogHandler)
+	stop := c.cancelOnDone(ctx)
+	fail := func(err error) (*Rows, error) {
+		stop()
+		restore()
+		err = c.cancelledError(ctx, err)
+		return nil, firstError(err, c.releaseReader(r))
+	}
+
+	in, out, err := c.iterCodecs2pX(r, q)
+	if err != nil {
+		return fail(err)
+	}
+
+	w := buff.NewWriter(c.writeMemory[:0])
+	err = c.encodeExecute2pX(w, q, in, out.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	w.BeginMessage(uint8(Sync))
+	w.EndMessage()
+
+	if e := c.soc.WriteAll(w.Unwrap()); e != nil {
+		return fail(&clientConnectionClosedError{err: e})
+	}
+
+	return &Rows{
+		conn:    c,
+		reader:  r,
+		done:    buff.NewSignal(),
+		q:       q,
+		desc:    *out,
+		ctx:     ctx,
+		stop:    stop,
+		restore: restore,
+	}, nil
+}
+
+// iterCodecs2pX returns the input encoder and the output descriptor for q.
+// The output decoder is built by Rows.Scan()
+// once the go type to decode into is known.
+func (c *protocolConnection) iterCodecs2pX(
+	r *buff.Reader,
+	q *query,
+) (codecs.Encoder, *descriptor.V2, error) {
+	if ids, ok := c.getCachedTypeIDs(q); ok {
+		in, inOK := c.inCodecCache.Get(ids.in)
+		out, outOK := descCache.Get(ids.out)
+		if inOK && outOK {
+			desc := out.(descriptor.V2)
+			return in.(codecs.Encoder), &desc, nil
+		}
+	}
+
+	descs, err := c.parse2pX(r, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	in, err := codecs.BuildEncoderV2(
+		&descs.In,
+		c.protocolVersion,
+		c.customScalars,
+	)
+	if err != nil {
+		return nil, nil, &invalidArgumentError{msg: err.Error()}
+	}
+
+	c.inCodecCache.Put(in.DescriptorID(), in)
+	return in, &descs.Out, nil
+}
@@ -0,0 +1,143 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryIter(t *testing.T) {
+	ctx := context.Background()
+	rows, err := client.QueryIter(
+		ctx,
+		"SELECT (a := <int64>$0 + x, b := <str>x) "+
+			"FOR x IN range_unpack(range(0, 1000))",
+		int64(1),
+	)
+	require.NoError(t, err)
+	defer rows.Close() // nolint:errcheck
+
+	type Row struct {
+		A int64  `edgedb:"a"`
+		B string `edgedb:"b"`
+	}
+
+	var n int64
+	for rows.Next() {
+		var row Row
+		require.NoError(t, rows.Scan(&row))
+		assert.Equal(t, n+1, row.A)
+		n++
+	}
+
+	require.NoError(t, rows.Err())
+	assert.Equal(t, int64(1000), n)
+	assert.NoError(t, rows.Close())
+}
+
+func TestQueryIterEarlyClose(t *testing.T) {
+	ctx := context.Background()
+	p, err := CreateClient(ctx, opts)
+	require.NoError(t, err)
+	defer p.Close() // nolint:errcheck
+
+	for i := 0; i < 10; i++ {
+		rows, e := p.QueryIter(
+			ctx, "SELECT range_unpack(range(0, 100_000))")
+		require.NoError(t, e)
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Close())
+		assert.False(t, rows.Next())
+	}
+
+	// The connections must be usable after closing the rows early.
+	var result int64
+	err = p.QuerySingle(ctx, "SELECT 7", &result)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), result)
+}
+
+func TestQueryIterError(t *testing.T) {
+	ctx := context.Background()
+	rows, err := client.QueryIter(
+		ctx, "SELECT 1 / x FOR x IN {1, 0}")
+	require.NoError(t, err)
+
+	for rows.Next() {
+	}
+
+	var edbErr Error
+	require.True(t, errors.As(rows.Err(), &edbErr), "wrong error: %v", err)
+	assert.True(t, edbErr.Category(DivisionByZeroError), "%v", rows.Err())
+	assert.Equal(t, rows.Err(), rows.Close())
+}
+
+func TestQueryIterScanMismatchedType(t *testing.T) {
+	ctx := context.Background()
+	rows, err := client.QueryIter(ctx, "SELECT {1, 2}")
+	require.NoError(t, err)
+	defer rows.Close() // nolint:errcheck
+
+	require.True(t, rows.Next())
+	var result string
+	err = rows.Scan(&result)
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"the \"out\" argument does not match query schema: "+
+		"expected string to be int64 or edgedb.OptionalInt64 got string")
+
+	var n int64
+	require.NoError(t, rows.Scan(&n))
+	assert.Equal(t, int64(1), n)
+}
+
+func TestQueryIterInTx(t *testing.T) {
+	ctx := context.Background()
+	var sum int64
+	err := client.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+		rows, e := tx.QueryIter(ctx, "SELECT {1, 2, 3}")
+		if e != nil {
+			return e
+		}
+
+		var result int64
+		e = tx.QuerySingle(ctx, "SELECT 1", &result)
+		assert.EqualError(t, e, "edgedb.InterfaceError: "+
+			"The connection is borrowed by open Rows. "+
+			"Close the Rows before running another query.")
+
+		for rows.Next() {
+			var n int64
+			if e := rows.Scan(&n); e != nil {
+				return e
+			}
+			sum += n
+		}
+
+		if e := rows.Close(); e != nil {
+			return e
+		}
+
+		return tx.QuerySingle(ctx, "SELECT 1", &result)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), sum)
+}
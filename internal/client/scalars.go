@@ -0,0 +1,48 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+// RegisterScalarCodec maps the scalar type named name, for example
+// default::Money, to a go type T. encode must be a func(T) (B, error) and
+// decode must be a func(B) (T, error) where B is a go type that the scalar's
+// base type can be encoded from and decoded into, for example string for a
+// scalar extending str.
+//
+// Query results of the scalar type, or of a scalar type extending it, can
+// then be decoded into T and query arguments of type T are encoded with
+// encode. The base type's go types can still be used as well. If *T
+// implements marshal.OptionalUnmarshaler, T can be used for optional shape
+// fields.
+//
+// Registered scalars are shared by all copies of the client. Register them
+// before running queries, queries prepared earlier with Client.Prepare()
+// keep using their codecs. Custom scalars require EdgeDB 5.0 or newer.
+func (p *Client) RegisterScalarCodec(
+	name string,
+	encode interface{},
+	decode interface{},
+) error {
+	if err := p.customScalars.Register(name, encode, decode); err != nil {
+		return &invalidArgumentError{msg: err.Error()}
+	}
+
+	// Codecs built before the scalar was registered
+	// don't know about the custom type.
+	p.inCodecCache.Invalidate()
+	p.outCodecCache.Invalidate()
+	return nil
+}
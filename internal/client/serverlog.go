@@ -0,0 +1,119 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+)
+
+// ServerLogSeverity is the severity of a ServerLogMessage.
+type ServerLogSeverity uint8
+
+const (
+	// ServerLogDebug is the severity of debug messages.
+	ServerLogDebug ServerLogSeverity = 0x14
+
+	// ServerLogInfo is the severity of informational messages.
+	ServerLogInfo ServerLogSeverity = 0x28
+
+	// ServerLogNotice is the severity of notices.
+	ServerLogNotice ServerLogSeverity = 0x3c
+
+	// ServerLogWarning is the severity of warnings.
+	ServerLogWarning ServerLogSeverity = 0x50
+)
+
+func (s ServerLogSeverity) String() string {
+	switch s {
+	case ServerLogDebug:
+		return "DEBUG"
+	case ServerLogInfo:
+		return "INFO"
+	case ServerLogNotice:
+		return "NOTICE"
+	case ServerLogWarning:
+		return "WARNING"
+	default:
+		return fmt.Sprintf("ServerLogSeverity(0x%x)", uint8(s))
+	}
+}
+
+// ServerLogMessage is a log message sent by the server.
+type ServerLogMessage struct {
+	Severity   ServerLogSeverity
+	Code       uint32
+	Text       string
+	Attributes map[string]string
+}
+
+// ServerLogHandler is invoked with log messages sent by the server. Messages
+// received while a query is running are passed to the handler of the client
+// or transaction that ran the query, other messages are passed to the handler
+// from Options.ServerLogHandler.
+type ServerLogHandler = func(ServerLogMessage)
+
+// LogServerMessages is an edgedb.ServerLogHandler that logs server messages
+// with the standard library's log package.
+func LogServerMessages(msg ServerLogMessage) {
+	log.Println("SERVER MESSAGE", msg.Severity, msg.Code, msg.Text)
+}
+
+func (c *protocolConnection) decodeLogMessage(r *buff.Reader) {
+	msg := ServerLogMessage{
+		Severity: ServerLogSeverity(r.PopUint8()),
+		Code:     r.PopUint32(),
+		Text:     r.PopString(),
+	}
+
+	n := int(r.PopUint16())
+	if n > 0 {
+		msg.Attributes = make(map[string]string, n)
+	}
+
+	for i := 0; i < n; i++ {
+		if c.protocolVersion.GTE(protocolVersion1p0) {
+			key := r.PopString()
+			msg.Attributes[key] = r.PopString()
+		} else {
+			key := strconv.Itoa(int(r.PopUint16()))
+			msg.Attributes[key] = string(r.PopBytes())
+		}
+	}
+
+	if c.serverLogHandler != nil {
+		c.serverLogHandler(msg)
+	}
+}
+
+// useServerLogHandler sets the handler for log messages received while
+// running a query. The returned function restores the previous handler,
+// it must be called before the reader is released.
+func (c *protocolConnection) useServerLogHandler(
+	handler ServerLogHandler,
+) (restore func()) {
+	if handler == nil {
+		return func() {}
+	}
+
+	previous := c.serverLogHandler
+	c.serverLogHandler = handler
+	return func() { c.serverLogHandler = previous }
+}
@@ -0,0 +1,55 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"testing"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeLogMessage(t *testing.T) {
+	var msgs []ServerLogMessage
+	c := &protocolConnection{
+		protocolVersion: protocolVersion2p0,
+		serverLogHandler: func(msg ServerLogMessage) {
+			msgs = append(msgs, msg)
+		},
+	}
+
+	r := buff.SimpleReader([]byte{
+		0x3c,       // severity
+		0, 0, 0, 7, // code
+		0, 0, 0, 2, 'h', 'i', // text
+		0, 1, // annotation count
+		0, 0, 0, 1, 'k', // annotation name
+		0, 0, 0, 1, 'v', // annotation value
+	})
+
+	restore := c.useServerLogHandler(nil)
+	c.decodeLogMessage(r)
+	restore()
+
+	assert.Empty(t, r.Buf)
+	assert.Equal(t, []ServerLogMessage{{
+		Severity:   ServerLogNotice,
+		Code:       7,
+		Text:       "hi",
+		Attributes: map[string]string{"k": "v"},
+	}}, msgs)
+}
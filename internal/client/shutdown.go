@@ -0,0 +1,149 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// activeConns is the set of connections that are acquired from a client.
+// It is shared by all copies of a client.
+type activeConns struct {
+	mu    sync.Mutex
+	conns map[*transactableConn]struct{}
+
+	// drained is closed when the last active connection is removed.
+	drained chan struct{}
+}
+
+func (a *activeConns) add(conn *transactableConn) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.conns == nil {
+		a.conns = make(map[*transactableConn]struct{})
+	}
+	a.conns[conn] = struct{}{}
+}
+
+func (a *activeConns) remove(conn *transactableConn) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.conns, conn)
+	if len(a.conns) == 0 && a.drained != nil {
+		close(a.drained)
+		a.drained = nil
+	}
+}
+
+// wait blocks until there are no active connections or ctx is done.
+func (a *activeConns) wait(ctx context.Context) error {
+	a.mu.Lock()
+	if len(a.conns) == 0 {
+		a.mu.Unlock()
+		return nil
+	}
+
+	if a.drained == nil {
+		a.drained = make(chan struct{})
+	}
+	drained := a.drained
+	a.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// forceClose closes the sockets of all active connections.
+func (a *activeConns) forceClose() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	errs := make([]error, 0, len(a.conns))
+	for conn := range a.conns {
+		errs = append(errs, conn.forceClose())
+	}
+
+	return wrapAll(errs...)
+}
+
+func (p *Client) stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+}
+
+// Shutdown gracefully closes the client. New queries and transactions are
+// rejected while queries and transactions that are already running are
+// allowed to finish. Connections are closed as they are released. If ctx is
+// done before all connections are released the remaining connections are
+// closed without waiting for their queries, and ctx's error is returned.
+//
+// Shutdown returns an error if the client is already closed.
+func (p *Client) Shutdown(ctx context.Context) (err error) {
+	// Stop callers that are waiting for a connection
+	// before waiting for them to release the lock.
+	p.stop()
+
+	p.isClosedMutex.Lock()
+	if *p.isClosed {
+		p.isClosedMutex.Unlock()
+		return &interfaceError{msg: "client closed"}
+	}
+	*p.isClosed = true
+	p.isClosedMutex.Unlock()
+
+	if p.replicas != nil {
+		defer func() { err = wrapAll(err, p.replicas.Shutdown(ctx)) }()
+	}
+
+	p.potentialConnsMutext.Lock()
+	initialized := p.potentialConns != nil
+	p.potentialConnsMutext.Unlock()
+
+	if !initialized {
+		// The client never made any connections.
+		return nil
+	}
+
+	if e := p.active.wait(ctx); e != nil {
+		err = wrapAll(fmt.Errorf("edgedb: %w", e), p.active.forceClose())
+	}
+
+	return wrapAll(err, p.closeIdleConns())
+}
+
+// closeIdleConns closes all connections that are not in use.
+func (p *Client) closeIdleConns() error {
+	var errs []error
+	for {
+		select {
+		case acquireIfNotTimedout := <-p.freeConns:
+			conn := acquireIfNotTimedout()
+			if conn != nil {
+				errs = append(errs, p.closeConn(conn, closedClient, nil))
+			}
+		default:
+			return wrapAll(errs...)
+		}
+	}
+}
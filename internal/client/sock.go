@@ -34,7 +34,16 @@ func connectAutoClosingSocket(
 		defer cancel()
 	}
 
-	conn, err := connectTLS(ctx, cfg)
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if cfg.addr.network == "unix" {
+		conn, err = connectUnix(ctx, cfg)
+	} else {
+		conn, err = connectTLS(ctx, cfg)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -51,13 +60,22 @@ func connectTLS(
 		return nil, err
 	}
 
-	d := tls.Dialer{Config: tlsConfig}
-	conn, err := d.DialContext(ctx, cfg.addr.network, cfg.addr.address)
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = defaultServerName(cfg.addr.address)
+	}
+
+	rawConn, err := dial(ctx, cfg)
 	if err != nil {
 		return nil, wrapNetError(err)
 	}
 
-	protocol := conn.(*tls.Conn).ConnectionState().NegotiatedProtocol
+	conn := tls.Client(rawConn, tlsConfig)
+	if err = conn.HandshakeContext(ctx); err != nil {
+		_ = rawConn.Close()
+		return nil, wrapNetError(err)
+	}
+
+	protocol := conn.ConnectionState().NegotiatedProtocol
 	if protocol != "edgedb-binary" {
 		_ = conn.Close()
 		return nil, &clientConnectionFailedError{
@@ -68,6 +86,52 @@ func connectTLS(
 	return conn, nil
 }
 
+// defaultServerName returns the host name of address. Same as tls.Dialer,
+// the TLS server name defaults to the host name.
+func defaultServerName(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+
+	return address
+}
+
+// connectUnix connects to a unix domain socket. Unix domain sockets are only
+// reachable from the local machine and access to them is controlled by file
+// permissions, so the connection does not use TLS.
+func connectUnix(
+	ctx context.Context,
+	cfg *connConfig,
+) (net.Conn, error) {
+	conn, err := dial(ctx, cfg)
+	if err != nil {
+		return nil, wrapNetError(err)
+	}
+
+	return conn, nil
+}
+
+// DialFunc connects to an address on the named network. It has the same
+// signature as net.Dialer.DialContext.
+type DialFunc = func(ctx context.Context, network, address string) (
+	net.Conn, error)
+
+// dial opens a connection to the server before any TLS handshake. It uses
+// cfg.dialer if set, otherwise it connects through cfg.proxy if set or
+// directly to the server.
+func dial(ctx context.Context, cfg *connConfig) (net.Conn, error) {
+	if cfg.dialer != nil {
+		return cfg.dialer(ctx, cfg.addr.network, cfg.addr.address)
+	}
+
+	var d net.Dialer
+	if cfg.proxy != nil {
+		return dialProxy(ctx, d.DialContext, cfg.proxy, cfg.addr.address)
+	}
+
+	return d.DialContext(ctx, cfg.addr.network, cfg.addr.address)
+}
+
 // autoClosingSocket closes itself on network errors and future read/write
 // operations fail immediately with an error.
 type autoClosingSocket struct {
@@ -0,0 +1,31 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultServerName(t *testing.T) {
+	assert.Equal(t, "localhost", defaultServerName("localhost:5656"))
+	assert.Equal(t, "10.0.0.1", defaultServerName("10.0.0.1:5656"))
+	assert.Equal(t, "::1", defaultServerName("[::1]:5656"))
+	assert.Equal(t, "fe80::1%eth0", defaultServerName("[fe80::1%eth0]:5656"))
+	assert.Equal(t, "localhost", defaultServerName("localhost"))
+}
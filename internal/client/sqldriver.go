@@ -0,0 +1,644 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edgedb/edgedb-go/internal/codecs"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+)
+
+// sqlLanguageParam is the DSN query parameter that selects the language
+// queries are written in. It is removed from the DSN before the DSN is
+// passed to CreateClientDSN.
+const sqlLanguageParam = "query_language"
+
+// SQLDriver is a database/sql/driver.Driver. DSNs have the same format as
+// the DSNs accepted by CreateClientDSN. Queries are EdgeQL unless the DSN
+// has the query parameter query_language=sql.
+type SQLDriver struct{}
+
+// Open returns a new connection to the database. Each connection returned by
+// Open uses its own Client. Use OpenConnector to share a Client between
+// connections.
+func (d SQLDriver) Open(dsn string) (driver.Conn, error) {
+	connector, err := d.openConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connector.connect(context.Background())
+	if err != nil {
+		return nil, firstError(err, connector.Close())
+	}
+
+	conn.closeClient = connector.Close
+	return conn, nil
+}
+
+// OpenConnector returns a connector with a new Client for dsn.
+// The Client is closed when the connector is closed.
+func (d SQLDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	return d.openConnector(dsn)
+}
+
+func (d SQLDriver) openConnector(dsn string) (*SQLConnector, error) {
+	dsn, lang, err := parseSQLDriverDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := CreateClientDSN(context.Background(), dsn, Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLConnector{client: client, lang: lang, ownsClient: true}, nil
+}
+
+// parseSQLDriverDSN removes the query language parameter from dsn.
+func parseSQLDriverDSN(dsn string) (string, Language, error) {
+	uri, err := url.Parse(dsn)
+	if err != nil || uri.RawQuery == "" {
+		// Let CreateClientDSN report invalid DSNs.
+		return dsn, EdgeQL, nil
+	}
+
+	query, err := url.ParseQuery(uri.RawQuery)
+	if err != nil {
+		return dsn, EdgeQL, nil
+	}
+
+	values, ok := query[sqlLanguageParam]
+	if !ok {
+		return dsn, EdgeQL, nil
+	}
+
+	delete(query, sqlLanguageParam)
+	uri.RawQuery = query.Encode()
+
+	var lang Language
+	switch strings.ToLower(values[0]) {
+	case "edgeql":
+		lang = EdgeQL
+	case "sql":
+		lang = SQL
+	default:
+		return "", 0, &configurationError{msg: fmt.Sprintf(
+			"invalid %v %q, expected edgeql or sql",
+			sqlLanguageParam, values[0])}
+	}
+
+	return uri.String(), lang, nil
+}
+
+// NewEdgeQLConnector returns a database/sql/driver.Connector that runs
+// EdgeQL queries with client. Each database/sql connection holds one of the
+// client's connections until it is closed.
+func NewEdgeQLConnector(client *Client) *SQLConnector {
+	return &SQLConnector{client: client, lang: EdgeQL}
+}
+
+// NewSQLConnector returns a database/sql/driver.Connector that runs
+// SQL queries with client. Each database/sql connection holds one of the
+// client's connections until it is closed.
+func NewSQLConnector(client *Client) *SQLConnector {
+	return &SQLConnector{client: client, lang: SQL}
+}
+
+// SQLConnector is a database/sql/driver.Connector backed by a Client.
+// Use it with sql.OpenDB().
+type SQLConnector struct {
+	client *Client
+	lang   Language
+
+	// ownsClient is true if the client was created by the connector.
+	ownsClient bool
+}
+
+// Connect acquires a connection from the client.
+func (c *SQLConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.connect(ctx)
+}
+
+func (c *SQLConnector) connect(ctx context.Context) (*sqlConn, error) {
+	conn, err := c.client.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{client: c.client, conn: conn, lang: c.lang}, nil
+}
+
+// Driver returns the connector's driver.
+func (c *SQLConnector) Driver() driver.Driver { return SQLDriver{} }
+
+// Close closes the connector's client if it was created by the connector.
+// Clients passed to NewEdgeQLConnector or NewSQLConnector are not closed.
+func (c *SQLConnector) Close() error {
+	if c.ownsClient {
+		return c.client.Close()
+	}
+
+	return nil
+}
+
+// sqlConn is a database/sql connection. database/sql never uses a
+// connection concurrently so sqlConn doesn't need to synchronize access.
+type sqlConn struct {
+	client *Client
+	conn   *transactableConn
+	lang   Language
+
+	// tx is the current transaction, if any.
+	tx *Tx
+
+	// err is set if the connection failed. Failed connections
+	// are discarded by database/sql and closed by the client.
+	err error
+
+	// closeClient closes the client after the connection is closed.
+	// It is only set for connections returned by SQLDriver.Open().
+	closeClient func() error
+}
+
+// check records connection errors so that the connection is discarded.
+func (c *sqlConn) check(err error) error {
+	if isClientConnectionError(err) {
+		c.err = err
+	}
+
+	return err
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return &sqlStmt{conn: c, query: query}, nil
+}
+
+func (c *sqlConn) PrepareContext(
+	_ context.Context,
+	query string,
+) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *sqlConn) Close() error {
+	var err error
+	if c.tx != nil {
+		err = c.endTx((*Tx).rollback)
+	}
+
+	err = firstError(err, c.client.release(c.conn, c.err))
+	if c.closeClient != nil {
+		err = firstError(err, c.closeClient())
+	}
+
+	return err
+}
+
+func (c *sqlConn) ResetSession(_ context.Context) error {
+	if c.err != nil {
+		return driver.ErrBadConn
+	}
+
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool { return c.err == nil }
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	q, err := c.newQuery("Execute", "SELECT 1;", nil)
+	if err != nil {
+		return err
+	}
+
+	return c.scriptFlow(ctx, q)
+}
+
+// CheckNamedValue passes arguments to the query's codecs unchanged
+// so that edgedb types like edgedb.UUID or edgedb.OptionalStr
+// can be used as arguments. The only exception is int, which is converted
+// to int64 like database/sql does. An int can only be used for int64
+// parameters, int16 and int32 parameters need int16 and int32 values.
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch v := nv.Value.(type) {
+	case driver.Valuer:
+		val, err := v.Value()
+		if err != nil {
+			return err
+		}
+		nv.Value = val
+	case int:
+		nv.Value = int64(v)
+	}
+
+	return nil
+}
+
+// sqlArgs converts database/sql arguments to query arguments.
+// Named arguments are passed as a map.
+func sqlArgs(
+	lang Language,
+	values []driver.NamedValue,
+) ([]interface{}, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	if values[0].Name == "" {
+		args := make([]interface{}, len(values))
+		for i, v := range values {
+			if v.Name != "" {
+				return nil, &invalidArgumentError{
+					msg: "cannot mix named and positional arguments",
+				}
+			}
+			args[i] = sqlArg(v.Value)
+		}
+
+		return args, nil
+	}
+
+	if lang == SQL {
+		return nil, &invalidArgumentError{
+			msg: "named arguments are not supported in SQL queries",
+		}
+	}
+
+	kwargs := make(map[string]interface{}, len(values))
+	for _, v := range values {
+		if v.Name == "" {
+			return nil, &invalidArgumentError{
+				msg: "cannot mix named and positional arguments",
+			}
+		}
+		kwargs[v.Name] = sqlArg(v.Value)
+	}
+
+	return []interface{}{kwargs}, nil
+}
+
+// sqlArg converts a nil argument to a missing optional argument.
+// Client methods don't accept nil arguments.
+func sqlArg(val driver.Value) interface{} {
+	if val == nil {
+		return codecs.MissingArg
+	}
+
+	return val
+}
+
+func (c *sqlConn) newQuery(
+	method string,
+	cmd string,
+	values []driver.NamedValue,
+) (*query, error) {
+	args, err := sqlArgs(c.lang, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return newQuery(
+		method,
+		cmd,
+		args,
+		c.conn.capabilities1pX(),
+		copyState(c.client.state),
+		nil,
+		true,
+		c.client.warningHandler,
+		c.client.serverLogHandler,
+		c.client.compilation,
+	)
+}
+
+func (c *sqlConn) scriptFlow(ctx context.Context, q *query) error {
+	if c.tx != nil {
+		return c.check(c.tx.scriptFlow(ctx, q))
+	}
+
+	return c.check(c.conn.scriptFlow(ctx, q))
+}
+
+func (c *sqlConn) ExecContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	method := "Execute"
+	if c.lang == SQL {
+		method = "ExecuteSQL"
+	}
+
+	q, err := c.newQuery(method, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.scriptFlow(ctx, q); err != nil {
+		return nil, err
+	}
+
+	// The number of affected rows is not reported by the server.
+	return driver.ResultNoRows, nil
+}
+
+func (c *sqlConn) QueryContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	method := "QueryIter"
+	if c.lang == SQL {
+		method = "QueryIterSQL"
+	}
+
+	q, err := c.newQuery(method, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows *Rows
+	if c.tx != nil {
+		rows, err = c.tx.queryIter(ctx, q)
+	} else {
+		rows, err = c.conn.queryIter(ctx, q)
+		if rows != nil {
+			rows.release = func(error) error { return nil }
+		}
+	}
+	if err != nil {
+		return nil, c.check(err)
+	}
+
+	release := rows.release
+	rows.release = func(err error) error {
+		c.check(err)
+		return release(err)
+	}
+
+	return newSQLRows(rows), nil
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlConn) BeginTx(
+	ctx context.Context,
+	opts driver.TxOptions,
+) (driver.Tx, error) {
+	if c.tx != nil {
+		return nil, &interfaceError{msg: "already in a transaction"}
+	}
+
+	txOpts, err := sqlTxOptions(c.client.txOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if e := c.conn.ensureConnection(ctx); e != nil {
+		return nil, c.check(e)
+	}
+
+	conn, err := c.conn.borrow("transaction")
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Tx{
+		borrowableConn:   borrowableConn{conn: conn},
+		txState:          &txState{},
+		options:          txOpts,
+		state:            copyState(c.client.state),
+		warningHandler:   c.client.warningHandler,
+		serverLogHandler: c.client.serverLogHandler,
+		compilation:      c.client.compilation,
+	}
+
+	if e := tx.start(ctx); e != nil {
+		return nil, firstError(c.check(e), c.conn.unborrow())
+	}
+
+	c.tx = tx
+	return &sqlTx{conn: c}, nil
+}
+
+// sqlTxOptions applies database/sql transaction options to opts.
+func sqlTxOptions(
+	opts TxOptions,
+	sqlOpts driver.TxOptions,
+) (TxOptions, error) {
+	switch level := sql.IsolationLevel(sqlOpts.Isolation); level {
+	case sql.LevelDefault, sql.LevelSerializable:
+	default:
+		return opts, &invalidArgumentError{msg: fmt.Sprintf(
+			"unsupported isolation level: %v", level)}
+	}
+
+	if sqlOpts.ReadOnly {
+		opts = opts.WithReadOnly(true)
+	}
+
+	return opts, nil
+}
+
+// endTx commits or rolls back the current transaction.
+func (c *sqlConn) endTx(end func(*Tx, context.Context) error) error {
+	if c.tx == nil {
+		return &interfaceError{msg: "not in a transaction"}
+	}
+
+	err := end(c.tx, context.Background())
+	c.tx = nil
+	return firstError(c.check(err), c.conn.unborrow())
+}
+
+type sqlTx struct {
+	conn *sqlConn
+}
+
+func (t *sqlTx) Commit() error { return t.conn.endTx((*Tx).commit) }
+
+func (t *sqlTx) Rollback() error { return t.conn.endTx((*Tx).rollback) }
+
+// sqlStmt is a statement. Queries are parsed by the server
+// when they are run so preparing a statement does nothing.
+type sqlStmt struct {
+	conn  *sqlConn
+	query string
+}
+
+func (s *sqlStmt) Close() error { return nil }
+
+// NumInput returns -1 because the number of arguments is not known.
+func (s *sqlStmt) NumInput() int { return -1 }
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) ExecContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *sqlStmt) QueryContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return values
+}
+
+// sqlResultColumn is the column name for results
+// that are not objects or tuples.
+const sqlResultColumn = "result"
+
+// sqlRows are the results of a query. Objects, tuples and SQL records have
+// a column for each field, other results have a single column.
+type sqlRows struct {
+	rows    *Rows
+	columns []string
+	types   []string
+
+	// fields is true if each result has a column for each field.
+	fields bool
+}
+
+func newSQLRows(rows *Rows) *sqlRows {
+	r := &sqlRows{rows: rows}
+	desc := &rows.desc
+
+	switch {
+	case desc.ID == descriptor.IDZero:
+		// The query doesn't return any results.
+	case desc.Type == descriptor.Object,
+		desc.Type == descriptor.SQLRecord,
+		desc.Type == descriptor.Tuple:
+		r.fields = true
+		r.columns = make([]string, len(desc.Fields))
+		r.types = make([]string, len(desc.Fields))
+		for i, field := range desc.Fields {
+			r.columns[i] = field.Name
+			r.types[i] = field.Desc.Name
+		}
+	default:
+		r.columns = []string{sqlResultColumn}
+		r.types = []string{desc.Name}
+	}
+
+	return r
+}
+
+func (r *sqlRows) Columns() []string { return r.columns }
+
+// ColumnTypeDatabaseTypeName returns the name of the column's type,
+// for example std::str. The name is empty for types without a name
+// like arrays and tuples.
+func (r *sqlRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.types[index]
+}
+
+func (r *sqlRows) Close() error { return r.rows.Close() }
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+
+		return io.EOF
+	}
+
+	var val interface{}
+	if err := r.rows.Scan(&val); err != nil {
+		return err
+	}
+
+	if !r.fields {
+		v, err := sqlValue(val)
+		dest[0] = v
+		return err
+	}
+
+	var err error
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for i, name := range r.columns {
+			if dest[i], err = sqlValue(v[name]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i := range r.columns {
+			if dest[i], err = sqlValue(v[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return &binaryProtocolError{
+			msg: fmt.Sprintf("unexpected result type %T", val),
+		}
+	}
+
+	return nil
+}
+
+// sqlValue converts a value decoded into an interface{} to a driver.Value.
+// Numbers are widened to int64 and float64. Other scalars like edgedb.UUID
+// and edgedb.Decimal are converted to their string representation, arrays,
+// tuples, objects and ranges are converted to json.
+func sqlValue(val interface{}) (driver.Value, error) {
+	switch v := val.(type) {
+	case nil, bool, int64, float64, string, []byte, time.Time:
+		return v, nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		return json.Marshal(v)
+	}
+}
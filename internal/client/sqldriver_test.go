@@ -0,0 +1,245 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/edgedb/edgedb-go/internal/codecs"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLDriverQuery(t *testing.T) {
+	ctx := context.Background()
+	db := sql.OpenDB(NewEdgeQLConnector(client))
+	defer db.Close() // nolint:errcheck
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT (a := <int64>$0 + x, b := <str>x) FOR x IN {1, 2, 3}",
+		int64(1))
+	require.NoError(t, err)
+	defer rows.Close() // nolint:errcheck
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, columns)
+
+	results := make(map[int64]string)
+	for rows.Next() {
+		var (
+			a int64
+			b string
+		)
+		require.NoError(t, rows.Scan(&a, &b))
+		results[a] = b
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, map[int64]string{2: "1", 3: "2", 4: "3"}, results)
+
+	var id string
+	err = db.QueryRowContext(ctx,
+		"SELECT <uuid>'759637d8-6635-11e9-b9d4-098002d459d5'").Scan(&id)
+	require.NoError(t, err)
+	assert.Equal(t, "759637d8-6635-11e9-b9d4-098002d459d5", id)
+
+	var name sql.NullString
+	err = db.QueryRowContext(ctx,
+		"SELECT <optional str>$name ?? 'default'",
+		sql.Named("name", nil)).Scan(&name)
+	require.NoError(t, err)
+	assert.Equal(t, sql.NullString{String: "default", Valid: true}, name)
+}
+
+func TestSQLDriverTx(t *testing.T) {
+	ctx := context.Background()
+	db := sql.OpenDB(NewEdgeQLConnector(client))
+	defer db.Close() // nolint:errcheck
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT TxTest {name := 'SQL driver roll back'}")
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	var count int64
+	err = db.QueryRowContext(ctx,
+		"SELECT count(TxTest FILTER .name = 'SQL driver roll back')",
+	).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	_, err = db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+	})
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"unsupported isolation level: Read Committed")
+}
+
+func TestSQLDriverSQLQuery(t *testing.T) {
+	ctx := context.Background()
+
+	var version int64
+	err := client.QuerySingle(ctx, "SELECT sys::get_version().major", &version)
+	require.NoError(t, err)
+	if version < 6 {
+		t.Skip("SQL queries require EdgeDB 6.0 or newer")
+	}
+
+	db := sql.OpenDB(NewSQLConnector(client))
+	defer db.Close() // nolint:errcheck
+
+	var foo, bar int64
+	err = db.QueryRowContext(ctx, "select 1 + $1::int8 AS foo, 2 AS bar",
+		int64(41)).Scan(&foo, &bar)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), foo)
+	assert.Equal(t, int64(2), bar)
+}
+
+func TestParseSQLDriverDSN(t *testing.T) {
+	samples := []struct {
+		dsn  string
+		out  string
+		lang Language
+		err  string
+	}{
+		{dsn: "", out: "", lang: EdgeQL},
+		{dsn: "my_instance", out: "my_instance", lang: EdgeQL},
+		{
+			dsn:  "edgedb://user@localhost/main?tls_security=insecure",
+			out:  "edgedb://user@localhost/main?tls_security=insecure",
+			lang: EdgeQL,
+		},
+		{
+			dsn:  "edgedb://localhost?query_language=sql&port=5656",
+			out:  "edgedb://localhost?port=5656",
+			lang: SQL,
+		},
+		{
+			dsn:  "edgedb://localhost?query_language=EdgeQL",
+			out:  "edgedb://localhost",
+			lang: EdgeQL,
+		},
+		{
+			dsn: "edgedb://localhost?query_language=graphql",
+			err: "edgedb.ConfigurationError: " +
+				`invalid query_language "graphql", expected edgeql or sql`,
+		},
+	}
+
+	for _, s := range samples {
+		t.Run(s.dsn, func(t *testing.T) {
+			out, lang, err := parseSQLDriverDSN(s.dsn)
+			if s.err != "" {
+				assert.EqualError(t, err, s.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, s.out, out)
+			assert.Equal(t, s.lang, lang)
+		})
+	}
+}
+
+func TestSQLArgs(t *testing.T) {
+	args, err := sqlArgs(EdgeQL, []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: "a"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(1), "a"}, args)
+
+	args, err = sqlArgs(EdgeQL, []driver.NamedValue{
+		{Name: "a", Ordinal: 1, Value: int64(1)},
+		{Name: "b", Ordinal: 2, Value: nil},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"a": int64(1), "b": codecs.MissingArg},
+	}, args)
+
+	_, err = sqlArgs(EdgeQL, []driver.NamedValue{
+		{Name: "a", Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: int64(2)},
+	})
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"cannot mix named and positional arguments")
+
+	_, err = sqlArgs(SQL, []driver.NamedValue{
+		{Name: "a", Ordinal: 1, Value: int64(1)},
+	})
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"named arguments are not supported in SQL queries")
+}
+
+func TestCheckNamedValue(t *testing.T) {
+	conn := &sqlConn{}
+	for _, c := range []struct {
+		in  interface{}
+		out interface{}
+	}{
+		{1, int64(1)},
+		{int16(1), int16(1)},
+		{int32(1), int32(1)},
+		{types.NewOptionalStr("a"), types.NewOptionalStr("a")},
+		{sql.NullInt64{Int64: 1, Valid: true}, int64(1)},
+		{nil, nil},
+	} {
+		nv := driver.NamedValue{Ordinal: 1, Value: c.in}
+		require.NoError(t, conn.CheckNamedValue(&nv))
+		assert.Equal(t, c.out, nv.Value, "%T", c.in)
+	}
+}
+
+func TestSQLValue(t *testing.T) {
+	id := types.UUID{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1}
+	now := time.Now()
+
+	samples := []struct {
+		in  interface{}
+		out driver.Value
+	}{
+		{nil, nil},
+		{true, true},
+		{int16(1), int64(1)},
+		{int32(2), int64(2)},
+		{int64(3), int64(3)},
+		{float32(0.5), float64(0.5)},
+		{"str", "str"},
+		{[]byte("bytes"), []byte("bytes")},
+		{now, now},
+		{id, "01020304-0506-0708-0807-060504030201"},
+		{types.Duration(1_000_000), "PT1S"},
+		{[]interface{}{int64(1), "a"}, []byte(`[1,"a"]`)},
+		{map[string]interface{}{"a": int64(1)}, []byte(`{"a":1}`)},
+	}
+
+	for _, s := range samples {
+		out, err := sqlValue(s.in)
+		require.NoError(t, err)
+		assert.Equal(t, s.out, out, "%#v", s.in)
+	}
+}
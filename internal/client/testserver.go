@@ -176,6 +176,11 @@ func initServer() {
 			COMMIT MIGRATION;
 		`)
 	}
+
+	if protocolVersion.GTE(protocolVersion2p0) {
+		// Used by the edgeql-go tests for ext::pgvector::vector.
+		execOrFatal(`CREATE EXTENSION pgvector;`)
+	}
 }
 
 func initServerInfo() {
@@ -26,6 +26,17 @@ type transactableConn struct {
 	*reconnectingConn
 	txOpts    TxOptions
 	retryOpts RetryOptions
+
+	// expiresAt is when the connection should be closed.
+	// Connections don't expire if expiresAt is zero.
+	expiresAt time.Time
+
+	// idleSince is when the connection was last released.
+	idleSince time.Time
+}
+
+func (c *transactableConn) expired() bool {
+	return !c.expiresAt.IsZero() && time.Now().After(c.expiresAt)
 }
 
 func (c *transactableConn) granularFlow(ctx context.Context, q *query) error {
@@ -51,6 +62,7 @@ func (c *transactableConn) granularFlow(ctx context.Context, q *query) error {
 		// error explicitly indicates a transaction conflict.
 		capabilities, ok := c.getCachedCapabilities(q)
 		if ok &&
+			ctx.Err() == nil &&
 			errors.As(err, &edbErr) &&
 			edbErr.HasTag(ShouldRetry) &&
 			(capabilities == 0 || edbErr.Category(TransactionConflictError)) {
@@ -78,6 +90,8 @@ func (c *transactableConn) tx(
 	action TxBlock,
 	state map[string]interface{},
 	warningHandler WarningHandler,
+	serverLogHandler ServerLogHandler,
+	compilation compilationOptions,
 ) (err error) {
 	conn, err := c.borrow("transaction")
 	if err != nil {
@@ -98,11 +112,13 @@ func (c *transactableConn) tx(
 
 		{
 			tx := &Tx{
-				borrowableConn: borrowableConn{conn: conn},
-				txState:        &txState{},
-				options:        c.txOpts,
-				state:          state,
-				warningHandler: warningHandler,
+				borrowableConn:   borrowableConn{conn: conn},
+				txState:          &txState{},
+				options:          c.txOpts,
+				state:            state,
+				warningHandler:   warningHandler,
+				serverLogHandler: serverLogHandler,
+				compilation:      compilation,
 			}
 			err = tx.start(ctx)
 			if err != nil {
@@ -128,7 +144,9 @@ func (c *transactableConn) tx(
 		}
 
 	Error:
-		if errors.As(err, &edbErr) && edbErr.HasTag(ShouldRetry) {
+		if ctx.Err() == nil &&
+			errors.As(err, &edbErr) &&
+			edbErr.HasTag(ShouldRetry) {
 			rule, e := c.retryOpts.ruleForException(edbErr)
 			if e != nil {
 				return e
@@ -19,6 +19,7 @@ package edgedb
 import (
 	"context"
 	"fmt"
+	"regexp"
 )
 
 // TxBlock is work to be done in a transaction.
@@ -76,9 +77,26 @@ func (s *txState) assertStarted(opName string) error {
 type Tx struct {
 	borrowableConn
 	*txState
-	options        TxOptions
-	state          map[string]interface{}
-	warningHandler WarningHandler
+	options          TxOptions
+	state            map[string]interface{}
+	warningHandler   WarningHandler
+	serverLogHandler ServerLogHandler
+	compilation      compilationOptions
+
+	// rows is set while Rows returned by QueryIter() are open.
+	rows *Rows
+
+	// nested is the number of savepoints declared by Nested().
+	// It is used to generate unique savepoint names.
+	nested int
+}
+
+// closeRows closes any Rows that are still open so that the transaction can
+// be committed or rolled back. Query errors are reported by Rows.Err().
+func (t *Tx) closeRows() {
+	if t.rows != nil {
+		_ = t.rows.Close()
+	}
 }
 
 func (t *Tx) execute(
@@ -95,6 +113,8 @@ func (t *Tx) execute(
 		nil,
 		false,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 	if err != nil {
 		return err
@@ -132,6 +152,7 @@ func (t *Tx) commit(ctx context.Context) error {
 		return e
 	}
 
+	t.closeRows()
 	return t.execute(ctx, "COMMIT;", committedTx)
 }
 
@@ -140,9 +161,104 @@ func (t *Tx) rollback(ctx context.Context) error {
 		return e
 	}
 
+	t.closeRows()
 	return t.execute(ctx, "ROLLBACK;", rolledBackTx)
 }
 
+var savepointNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// savepointCommand runs a savepoint command. Unlike execute() a failed
+// command does not put the transaction in an error state because the
+// transaction can still be recovered by rolling back to a savepoint.
+func (t *Tx) savepointCommand(
+	ctx context.Context,
+	opName string,
+	cmd string,
+	name string,
+) error {
+	if e := t.assertStarted(opName); e != nil {
+		return e
+	}
+
+	if !savepointNameRegexp.MatchString(name) {
+		return &invalidArgumentError{msg: fmt.Sprintf(
+			"invalid savepoint name %q, names must start with a letter "+
+				"or underscore and contain only letters, digits "+
+				"and underscores", name)}
+	}
+
+	q, err := newQuery(
+		"Execute",
+		// The name is quoted so that reserved keywords can be used.
+		fmt.Sprintf("%v `%v`;", cmd, name),
+		nil,
+		txCapabilities,
+		t.state,
+		nil,
+		false,
+		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
+	)
+	if err != nil {
+		return err
+	}
+
+	return t.borrowableConn.scriptFlow(ctx, q)
+}
+
+// Savepoint declares a savepoint with the given name.
+// Changes made after the savepoint can be undone with RollbackTo()
+// without rolling back the whole transaction.
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	return t.savepointCommand(ctx, "Savepoint", "DECLARE SAVEPOINT", name)
+}
+
+// RollbackTo rolls back all changes made after the named savepoint was
+// declared. It also recovers a transaction that is in an error state because
+// a query failed after the savepoint was declared.
+// The savepoint remains declared after rolling back to it.
+func (t *Tx) RollbackTo(ctx context.Context, name string) error {
+	return t.savepointCommand(
+		ctx,
+		"RollbackTo",
+		"ROLLBACK TO SAVEPOINT",
+		name,
+	)
+}
+
+// Release releases the named savepoint.
+// Changes made after the savepoint was declared are kept.
+func (t *Tx) Release(ctx context.Context, name string) error {
+	return t.savepointCommand(ctx, "Release", "RELEASE SAVEPOINT", name)
+}
+
+// Nested runs action inside a savepoint in the transaction.
+// If action returns an error the changes made by action are rolled back
+// and the error is returned, the rest of the transaction is not affected.
+// This allows code that needs a transaction
+// to be called with a transaction that is already started.
+//
+// Unlike Client.Tx() the action is not retried.
+func (t *Tx) Nested(ctx context.Context, action TxBlock) error {
+	t.nested++
+	name := fmt.Sprintf("_edgedb_go_nested_%v", t.nested)
+
+	if e := t.Savepoint(ctx, name); e != nil {
+		return e
+	}
+
+	err := action(ctx, t)
+	t.closeRows()
+	if err != nil {
+		if e := t.RollbackTo(ctx, name); e != nil {
+			return wrapAll(err, e)
+		}
+	}
+
+	return firstError(err, t.Release(ctx, name))
+}
+
 func (t *Tx) scriptFlow(ctx context.Context, q *query) error {
 	if e := t.assertStarted("Execute"); e != nil {
 		return e
@@ -174,6 +290,8 @@ func (t *Tx) Execute(
 		nil,
 		true,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 	if err != nil {
 		return err
@@ -198,7 +316,62 @@ func (t *Tx) Query(
 		args,
 		t.state,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
+	)
+}
+
+// QueryIter runs a query and returns an iterator over its results.
+// Unlike Query() the results are decoded as they are received
+// instead of being collected into a slice.
+// No other queries can be run in the transaction
+// until the returned Rows is closed.
+func (t *Tx) QueryIter(
+	ctx context.Context,
+	cmd string,
+	args ...interface{},
+) (*Rows, error) {
+	q, err := newQuery(
+		"QueryIter",
+		cmd,
+		args,
+		t.capabilities1pX(),
+		t.state,
+		nil,
+		true,
+		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.queryIter(ctx, q)
+}
+
+func (t *Tx) queryIter(ctx context.Context, q *query) (*Rows, error) {
+	if e := t.assertStarted(q.method); e != nil {
+		return nil, e
+	}
+
+	conn, err := t.borrow("rows")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.queryIter(ctx, q)
+	if err != nil {
+		return nil, firstError(err, t.unborrow())
+	}
+
+	t.rows = rows
+	rows.release = func(error) error {
+		t.rows = nil
+		return t.unborrow()
+	}
+
+	return rows, nil
 }
 
 // QuerySingle runs a singleton-returning query and returns its element.
@@ -220,6 +393,8 @@ func (t *Tx) QuerySingle(
 		args,
 		t.state,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 }
 
@@ -239,6 +414,8 @@ func (t *Tx) QueryJSON(
 		args,
 		t.state,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 }
 
@@ -260,6 +437,8 @@ func (t *Tx) QuerySingleJSON(
 		args,
 		t.state,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 }
 
@@ -278,6 +457,8 @@ func (t *Tx) ExecuteSQL(
 		nil,
 		true,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 	if err != nil {
 		return err
@@ -302,5 +483,7 @@ func (t *Tx) QuerySQL(
 		args,
 		t.state,
 		t.warningHandler,
+		t.serverLogHandler,
+		t.compilation,
 	)
 }
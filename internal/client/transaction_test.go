@@ -284,3 +284,102 @@ func TestSQLTx(t *testing.T) {
 		)
 	}
 }
+
+func TestTxSavepoint(t *testing.T) {
+	ctx := context.Background()
+	var names []string
+	err := client.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+		query := "INSERT TxTest {name := <str>$0};"
+		if e := tx.Execute(ctx, query, "Test Savepoint Kept"); e != nil {
+			return e
+		}
+
+		if e := tx.Savepoint(ctx, "sp1"); e != nil {
+			return e
+		}
+
+		if e := tx.Execute(ctx, query, "Test Savepoint Lost"); e != nil {
+			return e
+		}
+
+		// The transaction is recovered by rolling back to the savepoint.
+		e := tx.Execute(ctx, "SELECT 1 / 0;")
+		require.Error(t, e)
+
+		if e := tx.RollbackTo(ctx, "sp1"); e != nil {
+			return e
+		}
+
+		if e := tx.Release(ctx, "sp1"); e != nil {
+			return e
+		}
+
+		return tx.Query(
+			ctx,
+			`SELECT (
+				SELECT TxTest FILTER .name LIKE 'Test Savepoint%'
+			).name`,
+			&names,
+		)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Test Savepoint Kept"}, names)
+
+	// Reserved keywords are valid savepoint names.
+	err = client.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+		if e := tx.Savepoint(ctx, "select"); e != nil {
+			return e
+		}
+
+		if e := tx.RollbackTo(ctx, "select"); e != nil {
+			return e
+		}
+
+		return tx.Release(ctx, "select")
+	})
+	require.NoError(t, err)
+
+	err = client.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+		return tx.Savepoint(ctx, "bad name")
+	})
+	assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+		"invalid savepoint name \"bad name\", names must start with "+
+		"a letter or underscore and contain only letters, digits "+
+		"and underscores")
+}
+
+func TestTxNested(t *testing.T) {
+	ctx := context.Background()
+	var names []string
+	err := client.Tx(ctx, func(ctx context.Context, tx *Tx) error {
+		query := "INSERT TxTest {name := <str>$0};"
+		e := tx.Nested(ctx, func(ctx context.Context, tx *Tx) error {
+			return tx.Execute(ctx, query, "Test Nested Kept")
+		})
+		if e != nil {
+			return e
+		}
+
+		e = tx.Nested(ctx, func(ctx context.Context, tx *Tx) error {
+			if e := tx.Execute(ctx, query, "Test Nested Lost"); e != nil {
+				return e
+			}
+
+			return tx.Execute(ctx, "SELECT 1 / 0;")
+		})
+
+		var edbErr Error
+		require.True(t, errors.As(e, &edbErr), "wrong error: %v", e)
+		assert.True(t, edbErr.Category(DivisionByZeroError), "%v", e)
+
+		return tx.Query(
+			ctx,
+			`SELECT (
+				SELECT TxTest FILTER .name LIKE 'Test Nested%'
+			).name`,
+			&names,
+		)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Test Nested Kept"}, names)
+}
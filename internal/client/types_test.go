@@ -2731,6 +2731,24 @@ func TestSendAndReceiveEnum(t *testing.T) {
 	assert.EqualError(t, err, "rollback")
 }
 
+func TestSendAndReceiveEnumStringType(t *testing.T) {
+	type Color string
+
+	ddl := "CREATE SCALAR TYPE Color EXTENDING enum<Red, Green, Blue>;"
+	inRolledBackTx(t, ddl, func(ctx context.Context, tx *Tx) {
+		var result Color
+		err := tx.QuerySingle(ctx, "SELECT <Color>$0", &result, Color("Red"))
+		require.NoError(t, err)
+		assert.Equal(t, Color("Red"), result)
+
+		// Invalid values are rejected before the query is sent.
+		err = tx.QuerySingle(ctx, "SELECT <Color>$0", &result, "Purple")
+		assert.EqualError(t, err, "edgedb.InvalidArgumentError: "+
+			"invalid value \"Purple\" for default::Color at args[0], "+
+			"expected one of: Red, Green, Blue")
+	})
+}
+
 func TestReceiveEnumUnmarshaler(t *testing.T) {
 	ctx := context.Background()
 	var result struct {
@@ -5471,6 +5489,46 @@ func TestSendOptionalBigIntMarshaler(t *testing.T) {
 		"at args[0] expected at least 8, got 1")
 }
 
+func TestSendAndReceiveDecimal(t *testing.T) {
+	ctx := context.Background()
+
+	type Result struct {
+		Encoded   string                `edgedb:"encoded"`
+		Decoded   types.Decimal         `edgedb:"decoded"`
+		RoundTrip types.Decimal         `edgedb:"round_trip"`
+		Missing   types.OptionalDecimal `edgedb:"missing"`
+	}
+
+	query := `
+		SELECT (
+			encoded := <str><decimal>$0,
+			decoded := <decimal><str>$1,
+			round_trip := <decimal>$0,
+			missing := <decimal>{},
+		)`
+
+	for _, str := range []string{
+		"0",
+		"-15000.6250000",
+		"0.00000001",
+		"123456789012345678901234567890.123456789012345678901234567890",
+	} {
+		t.Run(str, func(t *testing.T) {
+			d, err := types.ParseDecimal(str)
+			require.NoError(t, err)
+
+			var result Result
+			err = client.QuerySingle(ctx, query, &result, d, str)
+			require.NoError(t, err)
+
+			assert.Equal(t, str, result.Encoded)
+			assert.Equal(t, str, result.Decoded.String())
+			assert.Equal(t, str, result.RoundTrip.String())
+			assert.Equal(t, types.OptionalDecimal{}, result.Missing)
+		})
+	}
+}
+
 type CustomDecimal struct {
 	data []byte
 }
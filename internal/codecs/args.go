@@ -18,11 +18,14 @@ package codecs
 
 import (
 	"fmt"
+	"reflect"
+	"unsafe"
 
 	"github.com/edgedb/edgedb-go/internal"
 	"github.com/edgedb/edgedb-go/internal/buff"
 	"github.com/edgedb/edgedb-go/internal/descriptor"
 	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/edgedb/edgedb-go/internal/introspect"
 )
 
 func buildArgEncoder(
@@ -54,11 +57,12 @@ func buildArgEncoder(
 func buildArgEncoderV2(
 	desc *descriptor.V2,
 	version internal.ProtocolVersion,
+	scalars *ScalarRegistry,
 ) (Encoder, error) {
 	fields := make([]*EncoderField, len(desc.Fields))
 
 	for i, field := range desc.Fields {
-		encoder, err := BuildEncoderV2(&field.Desc, version)
+		encoder, err := BuildEncoderV2(&field.Desc, version, scalars)
 		if err != nil {
 			return nil, err
 		}
@@ -110,7 +114,7 @@ func (c *argsEncoder) Encode(
 	var err error
 	for i, field := range c.fields {
 		w.PushUint32(0) // reserved
-		err = field.encoder.Encode(w, in[i], path.AddIndex(i), field.required)
+		err = encodeArg(w, field, in[i], path.AddIndex(i))
 		if err != nil {
 			return err
 		}
@@ -120,6 +124,32 @@ func (c *argsEncoder) Encode(
 	return nil
 }
 
+// MissingArg is an argument value that is encoded as a missing value. The
+// database/sql driver passes nil arguments as MissingArg and struct
+// arguments without a field for an optional parameter are MissingArg.
+var MissingArg = missingArg{}
+
+type missingArg struct{}
+
+// encodeArg encodes val as the argument for field.
+func encodeArg(
+	w *buff.Writer,
+	field *EncoderField,
+	val interface{},
+	path Path,
+) error {
+	if val != MissingArg {
+		return field.encoder.Encode(w, val, path, field.required)
+	}
+
+	if field.required {
+		return fmt.Errorf("missing required argument %v", path)
+	}
+
+	w.PushUint32(0xffffffff) // missing value
+	return nil
+}
+
 type kwargsEncoder struct {
 	id     types.UUID
 	fields []*EncoderField
@@ -144,27 +174,18 @@ func (c *kwargsEncoder) Encode(
 		)
 	}
 
-	in, ok := args[0].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf(
-			"expected %v to be map[string]interface{} got %T", path, args[0],
-		)
+	in, err := c.kwargs(args[0], path)
+	if err != nil {
+		return err
 	}
 
 	elmCount := len(c.fields)
 	w.BeginBytes()
 	w.PushUint32(uint32(elmCount))
 
-	var err error
 	for _, field := range c.fields {
 		w.PushUint32(0) // reserved
-		err = field.encoder.Encode(
-			w,
-			in[field.name],
-			path.AddField(field.name),
-			field.required,
-		)
-
+		err = encodeArg(w, field, in[field.name], path.AddField(field.name))
 		if err != nil {
 			return err
 		}
@@ -173,3 +194,58 @@ func (c *kwargsEncoder) Encode(
 	w.EndBytes()
 	return nil
 }
+
+// kwargs returns the named arguments in val. val can be a map with string
+// keys or a struct, or a pointer to a struct, with a field for each argument.
+// Struct fields are matched to arguments by their edgedb tag or their name.
+// Optional arguments without a struct field are missing.
+func (c *kwargsEncoder) kwargs(
+	val interface{},
+	path Path,
+) (map[string]interface{}, error) {
+	if in, ok := val.(map[string]interface{}); ok {
+		return in, nil
+	}
+
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		in := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			in[iter.Key().String()] = iter.Value().Interface()
+		}
+		return in, nil
+	case v.Kind() != reflect.Struct:
+		return nil, fmt.Errorf("expected %v to be map[string]interface{} "+
+			"or a struct got %T", path, val)
+	}
+
+	// Copy the struct so that its fields can be read by offset.
+	// Offsets are used because inlined fields are found by offset.
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+
+	in := make(map[string]interface{}, len(c.fields))
+	for _, field := range c.fields {
+		sf, ok := introspect.StructField(v.Type(), field.name)
+		if !ok {
+			if !field.required {
+				in[field.name] = MissingArg
+				continue
+			}
+
+			return nil, fmt.Errorf("expected %v to have a field "+
+				"with the tag `edgedb:\"%v\"`", v.Type(), field.name)
+		}
+
+		fieldPtr := unsafe.Add(ptr.UnsafePointer(), sf.Offset)
+		in[field.name] = reflect.NewAt(sf.Type, fieldPtr).Elem().Interface()
+	}
+
+	return in, nil
+}
@@ -0,0 +1,132 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"testing"
+
+	"github.com/edgedb/edgedb-go/internal"
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var kwargsDescV2 = descriptor.V2{
+	Type: descriptor.Object,
+	ID:   types.UUID{6},
+	Fields: []*descriptor.FieldV2{
+		{Name: "name", Desc: strDescV2, Required: true},
+		{Name: "email", Desc: strDescV2},
+	},
+}
+
+func encodeArgs(t *testing.T, args ...interface{}) ([]byte, error) {
+	encoder, err := BuildEncoderV2(
+		&kwargsDescV2,
+		internal.ProtocolVersion{Major: 2, Minor: 0},
+		nil,
+	)
+	require.NoError(t, err)
+
+	w := buff.NewWriter(nil)
+	w.BeginMessage(0)
+	err = encoder.Encode(w, args, Path("args"), true)
+	if err != nil {
+		return nil, err
+	}
+	w.EndMessage()
+
+	// skip the message type and length
+	return w.Unwrap()[5:], nil
+}
+
+func TestEncodeKwargs(t *testing.T) {
+	type Args struct {
+		Name  string            `edgedb:"name"`
+		Email types.OptionalStr `edgedb:"email"`
+	}
+
+	type Embedded struct {
+		Name string `edgedb:"name"`
+	}
+
+	type InlineArgs struct {
+		Embedded `edgedb:"$inline"`
+		Email    types.OptionalStr `edgedb:"email"`
+	}
+
+	expected, err := encodeArgs(t, map[string]interface{}{
+		"name":  "bob",
+		"email": types.OptionalStr{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0, 0, 0, 0x17, // data length
+		0, 0, 0, 2, // element count
+		0, 0, 0, 0, // reserved
+		0, 0, 0, 3, // data length
+		'b', 'o', 'b',
+		0, 0, 0, 0, // reserved
+		0xff, 0xff, 0xff, 0xff, // missing
+	}, expected)
+
+	for _, args := range []interface{}{
+		Args{Name: "bob"},
+		&Args{Name: "bob"},
+		InlineArgs{Embedded: Embedded{Name: "bob"}},
+		map[string]types.OptionalStr{
+			"name":  types.NewOptionalStr("bob"),
+			"email": {},
+		},
+		map[string]interface{}{"name": "bob", "email": MissingArg},
+		// Optional arguments can be left out of structs.
+		struct {
+			Name string `edgedb:"name"`
+		}{Name: "bob"},
+	} {
+		data, err := encodeArgs(t, args)
+		require.NoError(t, err)
+		assert.Equal(t, expected, data, "%T", args)
+	}
+}
+
+func TestEncodeKwargsMissingField(t *testing.T) {
+	type Args struct {
+		Email string `edgedb:"email"`
+	}
+
+	_, err := encodeArgs(t, Args{Email: "bob"})
+	assert.EqualError(t, err, "expected codecs.Args to have a field "+
+		"with the tag `edgedb:\"name\"`")
+
+	_, err = encodeArgs(t, map[string]interface{}{"email": "bob"})
+	assert.EqualError(t, err, "expected args.name to be string, "+
+		"edgedb.OptionalStr or StrMarshaler got <nil>")
+
+	_, err = encodeArgs(t, map[string]interface{}{"email": nil})
+	assert.EqualError(t, err, "expected args.name to be string, "+
+		"edgedb.OptionalStr or StrMarshaler got <nil>")
+
+	_, err = encodeArgs(t, map[string]interface{}{"name": MissingArg})
+	assert.EqualError(t, err, "missing required argument args.name")
+
+	_, err = encodeArgs(t, "bob")
+	assert.EqualError(t, err, "expected args to be map[string]interface{} "+
+		"or a struct got string")
+}
@@ -43,8 +43,9 @@ func buildArrayEncoder(
 func buildArrayEncoderV2(
 	desc *descriptor.V2,
 	version internal.ProtocolVersion,
+	scalars *ScalarRegistry,
 ) (Encoder, error) {
-	child, err := BuildEncoderV2(&desc.Fields[0].Desc, version)
+	child, err := BuildEncoderV2(&desc.Fields[0].Desc, version, scalars)
 
 	if err != nil {
 		return nil, err
@@ -131,6 +132,7 @@ func buildArrayDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
 	if typ.Kind() != reflect.Slice {
 		return nil, fmt.Errorf(
@@ -138,7 +140,12 @@ func buildArrayDecoderV2(
 		)
 	}
 
-	child, err := BuildDecoderV2(&desc.Fields[0].Desc, typ.Elem(), path)
+	child, err := BuildDecoderV2(
+		&desc.Fields[0].Desc,
+		typ.Elem(),
+		path,
+		scalars,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -110,9 +110,11 @@ func BuildEncoder(
 }
 
 // BuildEncoderV2 builds and Encoder from a Descriptor.
+// Scalars registered in scalars are encoded from their custom go types.
 func BuildEncoderV2(
 	desc *descriptor.V2,
 	version internal.ProtocolVersion,
+	scalars *ScalarRegistry,
 ) (Encoder, error) {
 	if desc.ID == descriptor.IDZero {
 		return noOpEncoder{}, nil
@@ -122,15 +124,15 @@ func BuildEncoderV2(
 	case descriptor.Set:
 		return nil, fmt.Errorf("sets can not be encoded")
 	case descriptor.Object:
-		return buildArgEncoderV2(desc, version)
+		return buildArgEncoderV2(desc, version, scalars)
 	case descriptor.BaseScalar, descriptor.Enum, descriptor.Scalar:
-		return BuildScalarEncoderV2(desc)
+		return buildCustomScalarEncoder(desc, scalars)
 	case descriptor.Tuple:
 		return nil, errors.New("tuples can not be encoded")
 	case descriptor.NamedTuple:
 		return nil, errors.New("tuples can not be encoded")
 	case descriptor.Array:
-		return buildArrayEncoderV2(desc, version)
+		return buildArrayEncoderV2(desc, version, scalars)
 	case descriptor.Range:
 		return buildRangeEncoderV2(desc, version)
 	case descriptor.MultiRange:
@@ -170,12 +172,8 @@ func BuildScalarEncoder(desc descriptor.Descriptor) (Encoder, error) {
 		desc = GetScalarDescriptor(desc)
 	}
 
-	if desc.ID == DecimalID {
-		return &decimalEncoder{}, nil
-	}
-
 	if desc.Type == descriptor.Enum {
-		return &StrCodec{desc.ID}, nil
+		return &enumEncoder{id: desc.ID, members: desc.EnumMembers}, nil
 	}
 
 	switch desc.ID {
@@ -196,9 +194,7 @@ func BuildScalarEncoder(desc descriptor.Descriptor) (Encoder, error) {
 	case Float64ID:
 		return &Float64Codec{}, nil
 	case DecimalID:
-		return nil, errors.New("decimal codec not implemented. " +
-			"Consider implementing your own edgedb.DecimalMarshaler " +
-			"and edgedb.DecimalUnmarshaler.")
+		return &DecimalCodec{}, nil
 	case BoolID:
 		return &BoolCodec{}, nil
 	case DateTimeID:
@@ -233,12 +229,16 @@ func BuildScalarEncoderV2(desc *descriptor.V2) (Encoder, error) {
 		desc = GetScalarDescriptorV2(desc)
 	}
 
-	if desc.ID == DecimalID {
-		return &decimalEncoder{}, nil
+	if desc.Type == descriptor.Enum {
+		return &enumEncoder{
+			id:      desc.ID,
+			name:    desc.Name,
+			members: desc.EnumMembers,
+		}, nil
 	}
 
-	if desc.Type == descriptor.Enum {
-		return &StrCodec{desc.ID}, nil
+	if desc.Name == VectorName {
+		return &VectorCodec{desc.ID}, nil
 	}
 
 	switch desc.ID {
@@ -259,9 +259,7 @@ func BuildScalarEncoderV2(desc *descriptor.V2) (Encoder, error) {
 	case Float64ID:
 		return &Float64Codec{}, nil
 	case DecimalID:
-		return nil, errors.New("decimal codec not implemented. " +
-			"Consider implementing your own edgedb.DecimalMarshaler " +
-			"and edgedb.DecimalUnmarshaler.")
+		return &DecimalCodec{}, nil
 	case BoolID:
 		return &BoolCodec{}, nil
 	case DateTimeID:
@@ -323,28 +321,34 @@ func BuildDecoder(
 }
 
 // BuildDecoderV2 builds a Decoder from a Descriptor.
+// Scalars registered in scalars are decoded into their custom go types.
 func BuildDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
 	if desc.ID == descriptor.IDZero {
 		return noOpDecoder{}, nil
 	}
 
+	if isDynamicV2(desc, typ, scalars) {
+		return buildDynamicDecoderV2(desc, typ, path, scalars)
+	}
+
 	switch desc.Type {
 	case descriptor.Set:
-		return buildSetDecoderV2(desc, typ, path)
+		return buildSetDecoderV2(desc, typ, path, scalars)
 	case descriptor.Object, descriptor.SQLRecord:
-		return buildObjectDecoderV2(desc, typ, path)
+		return buildObjectDecoderV2(desc, typ, path, scalars)
 	case descriptor.BaseScalar, descriptor.Enum, descriptor.Scalar:
-		return buildScalarDecoderV2(desc, typ, path)
+		return buildScalarDecoderV2(desc, typ, path, scalars)
 	case descriptor.Tuple:
-		return buildTupleDecoderV2(desc, typ, path)
+		return buildTupleDecoderV2(desc, typ, path, scalars)
 	case descriptor.NamedTuple:
-		return buildNamedTupleDecoderV2(desc, typ, path)
+		return buildNamedTupleDecoderV2(desc, typ, path, scalars)
 	case descriptor.Array:
-		return buildArrayDecoderV2(desc, typ, path)
+		return buildArrayDecoderV2(desc, typ, path, scalars)
 	case descriptor.Range:
 		return buildRangeDecoderV2(desc, typ, path)
 	case descriptor.MultiRange:
@@ -376,11 +380,12 @@ func buildScalarDecoder(
 	var expectedType string
 
 	if desc.Type == descriptor.Enum {
-		switch typ {
-		case strType:
-			return &StrCodec{desc.ID}, nil
-		case optionalStrType:
+		switch {
+		case typ == optionalStrType:
 			return &optionalStrDecoder{StrID}, nil
+		case typ.Kind() == reflect.String:
+			// string or a user defined string type like type Color string
+			return &StrCodec{desc.ID}, nil
 		default:
 			expectedType = "string or edgedb.OptionalStr"
 			goto TypeMissmatch
@@ -461,9 +466,14 @@ func buildScalarDecoder(
 			expectedType = "float64 or edgedb.OptionalFloat64"
 		}
 	case DecimalID:
-		return nil, errors.New("decimal codec not implemented. " +
-			"Consider implementing your own edgedb.DecimalMarshaler " +
-			"and edgedb.DecimalUnmarshaler.")
+		switch typ {
+		case decimalType:
+			return &DecimalCodec{}, nil
+		case optionalDecimalType:
+			return &optionalDecimalDecoder{}, nil
+		default:
+			expectedType = "edgedb.Decimal or edgedb.OptionalDecimal"
+		}
 	case BoolID:
 		switch typ {
 		case boolType:
@@ -591,7 +601,16 @@ func buildScalarDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
+	custom, ok, err := buildCustomScalarDecoder(desc, typ, path, scalars)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return custom, nil
+	}
+
 	if desc.Type == descriptor.Scalar {
 		desc = GetScalarDescriptorV2(desc)
 	}
@@ -607,17 +626,22 @@ func buildScalarDecoderV2(
 	var expectedType string
 
 	if desc.Type == descriptor.Enum {
-		switch typ {
-		case strType:
-			return &StrCodec{desc.ID}, nil
-		case optionalStrType:
+		switch {
+		case typ == optionalStrType:
 			return &optionalStrDecoder{StrID}, nil
+		case typ.Kind() == reflect.String:
+			// string or a user defined string type like type Color string
+			return &StrCodec{desc.ID}, nil
 		default:
 			expectedType = "string or edgedb.OptionalStr"
 			goto TypeMissmatch
 		}
 	}
 
+	if desc.Name == VectorName {
+		return buildVectorDecoderV2(desc, typ, path)
+	}
+
 	switch desc.ID {
 	case UUIDID:
 		switch typ {
@@ -692,9 +716,14 @@ func buildScalarDecoderV2(
 			expectedType = "float64 or edgedb.OptionalFloat64"
 		}
 	case DecimalID:
-		return nil, errors.New("decimal codec not implemented. " +
-			"Consider implementing your own edgedb.DecimalMarshaler " +
-			"and edgedb.DecimalUnmarshaler.")
+		switch typ {
+		case decimalType:
+			return &DecimalCodec{}, nil
+		case optionalDecimalType:
+			return &optionalDecimalDecoder{}, nil
+		default:
+			expectedType = "edgedb.Decimal or edgedb.OptionalDecimal"
+		}
 	case BoolID:
 		switch typ {
 		case boolType:
@@ -95,8 +95,12 @@ var (
 	relativeDurationType      = reflect.TypeOf(types.RelativeDuration{})
 	dateDurationType          = reflect.TypeOf(types.DateDuration{})
 	bigIntType                = reflect.TypeOf(&big.Int{})
+	decimalType               = reflect.TypeOf(types.Decimal{})
 	memoryType                = reflect.TypeOf(types.Memory(0))
 	optionalBigIntType        = reflect.TypeOf(types.OptionalBigInt{})
+	optionalDecimalType       = reflect.TypeOf(types.OptionalDecimal{})
+	vectorType                = reflect.TypeOf([]float32{})
+	optionalVectorType        = reflect.TypeOf(types.OptionalVector{})
 	optionalDateTimeType      = reflect.TypeOf(types.OptionalDateTime{})
 	optionalLocalDateTimeType = reflect.TypeOf(
 		types.OptionalLocalDateTime{})
@@ -0,0 +1,278 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// NewScalarRegistry returns an empty ScalarRegistry.
+func NewScalarRegistry() *ScalarRegistry {
+	return &ScalarRegistry{scalars: make(map[string]*customScalar)}
+}
+
+// ScalarRegistry maps scalar type names like default::Money to user defined
+// go types. It is safe for concurrent use. A nil *ScalarRegistry is empty.
+type ScalarRegistry struct {
+	mx      sync.RWMutex
+	scalars map[string]*customScalar
+}
+
+// customScalar converts between a user defined go type
+// and the go type of the scalar's base type.
+type customScalar struct {
+	// typ is the user defined type.
+	typ reflect.Type
+
+	// base is the go type that the base scalar codec encodes/decodes.
+	base reflect.Type
+
+	// encode is a func(typ) (base, error)
+	encode reflect.Value
+
+	// decode is a func(base) (typ, error)
+	decode reflect.Value
+}
+
+// Register adds a custom scalar type. encode must be a func(T) (B, error)
+// and decode must be a func(B) (T, error) where T is the user defined type
+// and B is a type that the scalar's base type can be encoded from and
+// decoded into, for example string for scalars extending str.
+func (r *ScalarRegistry) Register(
+	name string,
+	encode interface{},
+	decode interface{},
+) error {
+	if name == "" {
+		return fmt.Errorf("scalar type name must not be empty")
+	}
+
+	enc := reflect.ValueOf(encode)
+	if !isConverter(enc) {
+		return fmt.Errorf("expected encode to be func(T) (B, error) got %T",
+			encode)
+	}
+
+	dec := reflect.ValueOf(decode)
+	if !isConverter(dec) {
+		return fmt.Errorf("expected decode to be func(B) (T, error) got %T",
+			decode)
+	}
+
+	typ := enc.Type().In(0)
+	base := enc.Type().Out(0)
+	if dec.Type().In(0) != base || dec.Type().Out(0) != typ {
+		return fmt.Errorf(
+			"expected decode to be func(%v) (%v, error) got %v",
+			base, typ, dec.Type())
+	}
+
+	if typ.Kind() == reflect.Interface {
+		return fmt.Errorf(
+			"custom scalar type for %v must not be an interface got %v",
+			name, typ)
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.scalars[name] = &customScalar{
+		typ:    typ,
+		base:   base,
+		encode: enc,
+		decode: dec,
+	}
+
+	return nil
+}
+
+// isConverter returns true if fn is a func(A) (B, error).
+func isConverter(fn reflect.Value) bool {
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return false
+	}
+
+	t := fn.Type()
+	return t.NumIn() == 1 &&
+		!t.IsVariadic() &&
+		t.NumOut() == 2 &&
+		t.Out(1) == errorType
+}
+
+// lookup finds the custom scalar registered for desc's type name or the
+// name of the closest ancestor that has a registered custom scalar.
+func (r *ScalarRegistry) lookup(desc *descriptor.V2) (*customScalar, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	if len(r.scalars) == 0 {
+		return nil, false
+	}
+
+	if s, ok := r.scalars[desc.Name]; ok && desc.Name != "" {
+		return s, true
+	}
+
+	for i := range desc.Ancestors {
+		name := desc.Ancestors[i].Desc.Name
+		if s, ok := r.scalars[name]; ok && name != "" {
+			return s, true
+		}
+	}
+
+	return nil, false
+}
+
+func buildCustomScalarEncoder(
+	desc *descriptor.V2,
+	scalars *ScalarRegistry,
+) (Encoder, error) {
+	encoder, err := BuildScalarEncoderV2(desc)
+	if err != nil {
+		return nil, err
+	}
+
+	if s, ok := scalars.lookup(desc); ok {
+		return &customScalarEncoder{encoder: encoder, scalar: s}, nil
+	}
+
+	return encoder, nil
+}
+
+// customScalarEncoder encodes user defined types by converting them to
+// their base type's go type. Other values are passed to the base encoder
+// unchanged.
+type customScalarEncoder struct {
+	encoder Encoder
+	scalar  *customScalar
+}
+
+func (c *customScalarEncoder) DescriptorID() types.UUID {
+	return c.encoder.DescriptorID()
+}
+
+// Encode encodes a value.
+func (c *customScalarEncoder) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	if val == nil || reflect.TypeOf(val) != c.scalar.typ {
+		return c.encoder.Encode(w, val, path, required)
+	}
+
+	result := c.scalar.encode.Call([]reflect.Value{reflect.ValueOf(val)})
+	if err := result[1].Interface(); err != nil {
+		return fmt.Errorf("cannot encode %v: %v", path, err)
+	}
+
+	return c.encoder.Encode(w, result[0].Interface(), path, required)
+}
+
+// buildCustomScalarDecoder returns a decoder for typ if it is the go type of
+// a custom scalar registered for desc.
+func buildCustomScalarDecoder(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+	scalars *ScalarRegistry,
+) (Decoder, bool, error) {
+	s, ok := scalars.lookup(desc)
+	if !ok || typ != s.typ {
+		return nil, false, nil
+	}
+
+	decoder, err := buildScalarDecoderV2(desc, s.base, path, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	custom := customScalarDecoder{decoder: decoder, scalar: s}
+	if reflect.PointerTo(typ).Implements(optionalUnmarshalerType) {
+		return &optionalCustomScalarDecoder{custom}, true, nil
+	}
+
+	return &custom, true, nil
+}
+
+// customScalarDecoder decodes into the base type's go type
+// and then converts the value to the user defined type.
+type customScalarDecoder struct {
+	decoder Decoder
+	scalar  *customScalar
+}
+
+func (c *customScalarDecoder) DescriptorID() types.UUID {
+	return c.decoder.DescriptorID()
+}
+
+// Decode decodes a value.
+func (c *customScalarDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	base := reflect.New(c.scalar.base)
+	if err := c.decoder.Decode(r, base.UnsafePointer()); err != nil {
+		return err
+	}
+
+	result := c.scalar.decode.Call([]reflect.Value{base.Elem()})
+	if err := result[1].Interface(); err != nil {
+		return err.(error)
+	}
+
+	reflect.NewAt(c.scalar.typ, out).Elem().Set(result[0])
+	return nil
+}
+
+type optionalCustomScalarDecoder struct {
+	customScalarDecoder
+}
+
+func (c *optionalCustomScalarDecoder) DecodeMissing(out unsafe.Pointer) {
+	val := reflect.NewAt(c.scalar.typ, out)
+	method := val.MethodByName("SetMissing")
+	method.Call([]reflect.Value{trueValue})
+}
+
+func (c *optionalCustomScalarDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	if err := c.customScalarDecoder.Decode(r, out); err != nil {
+		return err
+	}
+
+	val := reflect.NewAt(c.scalar.typ, out)
+	method := val.MethodByName("SetMissing")
+	method.Call([]reflect.Value{falseValue})
+	return nil
+}
@@ -0,0 +1,286 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal"
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	countryCodeDescV2 = descriptor.V2{
+		Type:      descriptor.Scalar,
+		ID:        types.UUID{9},
+		Name:      "default::CountryCode",
+		Ancestors: []*descriptor.FieldV2{{Desc: strDescV2}},
+	}
+)
+
+type countryCode struct {
+	code string
+}
+
+func encodeCountryCode(c countryCode) (string, error) {
+	if len(c.code) != 2 {
+		return "", errors.New("country codes must have 2 letters")
+	}
+
+	return c.code, nil
+}
+
+func decodeCountryCode(s string) (countryCode, error) {
+	return countryCode{s}, nil
+}
+
+type optionalCountryCode struct {
+	countryCode
+	missing bool
+}
+
+func (o *optionalCountryCode) SetMissing(missing bool) { o.missing = missing }
+
+func newCountryCodeRegistry(t *testing.T) *ScalarRegistry {
+	scalars := NewScalarRegistry()
+	err := scalars.Register(
+		"default::CountryCode",
+		encodeCountryCode,
+		decodeCountryCode,
+	)
+	require.NoError(t, err)
+
+	err = scalars.Register(
+		"default::OptionalCountryCode",
+		func(o optionalCountryCode) (string, error) { return o.code, nil },
+		func(s string) (optionalCountryCode, error) {
+			return optionalCountryCode{countryCode: countryCode{s}}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	return scalars
+}
+
+func TestRegisterScalarInvalidFuncs(t *testing.T) {
+	samples := []struct {
+		name     string
+		encode   interface{}
+		decode   interface{}
+		expected string
+	}{
+		{
+			name:     "",
+			encode:   encodeCountryCode,
+			decode:   decodeCountryCode,
+			expected: "scalar type name must not be empty",
+		},
+		{
+			name:   "default::CountryCode",
+			encode: func(countryCode) string { return "" },
+			decode: decodeCountryCode,
+			expected: "expected encode to be func(T) (B, error) " +
+				"got func(codecs.countryCode) string",
+		},
+		{
+			name:   "default::CountryCode",
+			encode: encodeCountryCode,
+			decode: "not a func",
+			expected: "expected decode to be func(B) (T, error) " +
+				"got string",
+		},
+		{
+			name:   "default::CountryCode",
+			encode: encodeCountryCode,
+			decode: func(int64) (countryCode, error) {
+				return countryCode{}, nil
+			},
+			expected: "expected decode to be " +
+				"func(string) (codecs.countryCode, error) " +
+				"got func(int64) (codecs.countryCode, error)",
+		},
+		{
+			name:   "default::CountryCode",
+			encode: func(interface{}) (string, error) { return "", nil },
+			decode: func(string) (interface{}, error) { return nil, nil },
+			expected: "custom scalar type for default::CountryCode " +
+				"must not be an interface got interface {}",
+		},
+	}
+
+	for _, sample := range samples {
+		t.Run(sample.expected, func(t *testing.T) {
+			scalars := NewScalarRegistry()
+			err := scalars.Register(sample.name, sample.encode, sample.decode)
+			assert.EqualError(t, err, sample.expected)
+		})
+	}
+}
+
+func TestEncodeCustomScalar(t *testing.T) {
+	encoder, err := BuildEncoderV2(
+		&countryCodeDescV2,
+		internal.ProtocolVersion{Major: 2, Minor: 0},
+		newCountryCodeRegistry(t),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, StrID, encoder.DescriptorID())
+
+	expected := []byte{0, 0, 0, 2, 'U', 'S'}
+	for _, val := range []interface{}{countryCode{"US"}, "US"} {
+		w := buff.NewWriter(nil)
+		err = encoder.Encode(w, val, Path("code"), true)
+		require.NoError(t, err)
+		assert.Equal(t, expected, w.Unwrap())
+	}
+
+	w := buff.NewWriter(nil)
+	err = encoder.Encode(w, countryCode{"USA"}, Path("code"), true)
+	assert.EqualError(t, err,
+		"cannot encode code: country codes must have 2 letters")
+}
+
+func TestEncodeUnregisteredScalar(t *testing.T) {
+	encoder, err := BuildEncoderV2(
+		&countryCodeDescV2,
+		internal.ProtocolVersion{Major: 2, Minor: 0},
+		nil,
+	)
+	require.NoError(t, err)
+
+	w := buff.NewWriter(nil)
+	err = encoder.Encode(w, countryCode{"US"}, Path("code"), true)
+	assert.EqualError(t, err, "expected code to be string, "+
+		"edgedb.OptionalStr or StrMarshaler got codecs.countryCode")
+}
+
+func decodeCustomScalar(
+	t *testing.T,
+	scalars *ScalarRegistry,
+	out interface{},
+	data []byte,
+) {
+	typ := reflect.TypeOf(out).Elem()
+	decoder, err := BuildDecoderV2(
+		&countryCodeDescV2,
+		typ,
+		Path(typ.String()),
+		scalars,
+	)
+	require.NoError(t, err)
+
+	r := buff.SimpleReader(data)
+	err = decoder.Decode(r, unsafe.Pointer(reflect.ValueOf(out).Pointer()))
+	require.NoError(t, err)
+}
+
+func TestDecodeCustomScalar(t *testing.T) {
+	scalars := newCountryCodeRegistry(t)
+	data := []byte{'U', 'S'}
+
+	var code countryCode
+	decodeCustomScalar(t, scalars, &code, data)
+	assert.Equal(t, countryCode{"US"}, code)
+
+	// The base type's go types can still be used.
+	var str string
+	decodeCustomScalar(t, scalars, &str, data)
+	assert.Equal(t, "US", str)
+
+	_, err := BuildDecoderV2(
+		&countryCodeDescV2,
+		reflect.TypeOf(code),
+		Path("out"),
+		nil,
+	)
+	assert.EqualError(t, err,
+		"expected out to be string or edgedb.OptionalStr "+
+			"got codecs.countryCode")
+}
+
+func TestDecodeCustomScalarIntoInterface(t *testing.T) {
+	scalars := newCountryCodeRegistry(t)
+	data := []byte{'U', 'S'}
+
+	var code interface{}
+	decodeCustomScalar(t, scalars, &code, data)
+	assert.Equal(t, countryCode{"US"}, code)
+
+	// Unregistered scalars use the base type's go type.
+	decodeCustomScalar(t, nil, &code, data)
+	assert.Equal(t, "US", code)
+}
+
+func TestDecodeOptionalCustomScalar(t *testing.T) {
+	scalars := newCountryCodeRegistry(t)
+	desc := countryCodeDescV2
+	desc.Name = "default::OptionalCountryCode"
+
+	decoder, err := BuildDecoderV2(
+		&desc,
+		reflect.TypeOf(optionalCountryCode{}),
+		Path("out"),
+		scalars,
+	)
+	require.NoError(t, err)
+
+	optional, ok := decoder.(OptionalDecoder)
+	require.True(t, ok, "expected decoder to be an OptionalDecoder")
+
+	var code optionalCountryCode
+	optional.DecodeMissing(unsafe.Pointer(&code))
+	assert.True(t, code.missing)
+
+	r := buff.SimpleReader([]byte{'U', 'S'})
+	err = optional.Decode(r, unsafe.Pointer(&code))
+	require.NoError(t, err)
+	assert.Equal(t, optionalCountryCode{countryCode: countryCode{"US"}}, code)
+}
+
+func TestDecodeCustomScalarSubtype(t *testing.T) {
+	// default::EUCountryCode extends default::CountryCode
+	desc := descriptor.V2{
+		Type: descriptor.Scalar,
+		ID:   types.UUID{10},
+		Name: "default::EUCountryCode",
+		Ancestors: []*descriptor.FieldV2{
+			{Desc: countryCodeDescV2},
+			{Desc: strDescV2},
+		},
+	}
+
+	decoder, err := BuildDecoderV2(
+		&desc,
+		reflect.TypeOf(countryCode{}),
+		Path("out"),
+		newCountryCodeRegistry(t),
+	)
+	require.NoError(t, err)
+
+	var code countryCode
+	r := buff.SimpleReader([]byte{'D', 'E'})
+	err = decoder.Decode(r, unsafe.Pointer(&code))
+	require.NoError(t, err)
+	assert.Equal(t, countryCode{"DE"}, code)
+}
@@ -0,0 +1,430 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+var (
+	anyType      = reflect.TypeOf((*interface{})(nil)).Elem()
+	mapAnyType   = reflect.TypeOf(map[string]interface{}{})
+	sliceAnyType = reflect.TypeOf([]interface{}{})
+)
+
+// isDynamicV2 returns true if typ is one of the generic types
+// that the result for desc can be decoded into
+// without a user defined go type.
+func isDynamicV2(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	scalars *ScalarRegistry,
+) bool {
+	switch typ {
+	case anyType:
+		// Custom scalars are decoded into their registered go types.
+		if _, ok := scalars.lookup(desc); ok {
+			return true
+		}
+
+		// json is already unmarshaled into interface{} values
+		// by the json decoders.
+		return !isJSONV2(desc)
+	case mapAnyType:
+		switch desc.Type {
+		case descriptor.Object, descriptor.SQLRecord:
+			return true
+		case descriptor.Tuple:
+			return isNamedTupleV2(desc)
+		}
+	case sliceAnyType:
+		return desc.Type == descriptor.Tuple && !isNamedTupleV2(desc)
+	}
+
+	return false
+}
+
+func isJSONV2(desc *descriptor.V2) bool {
+	switch desc.Type {
+	case descriptor.BaseScalar, descriptor.Scalar:
+		return GetScalarDescriptorV2(desc).ID == JSONID
+	default:
+		return false
+	}
+}
+
+// isNamedTupleV2 returns true if desc is a named tuple. PopV2() uses the
+// Tuple type for both tuples and named tuples, so they are told apart by
+// their field names.
+func isNamedTupleV2(desc *descriptor.V2) bool {
+	for i, field := range desc.Fields {
+		if field.Name != strconv.Itoa(i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func buildDynamicDecoderV2(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+	scalars *ScalarRegistry,
+) (Decoder, error) {
+	switch typ {
+	case mapAnyType:
+		return buildMapDecoderV2(desc, path, scalars)
+	case sliceAnyType:
+		return buildTupleSliceDecoderV2(desc, path, scalars)
+	default:
+		return buildAnyDecoderV2(desc, path, scalars)
+	}
+}
+
+// buildAnyDecoderV2 builds a decoder that decodes into an interface{} using
+// the natural go type for desc.
+func buildAnyDecoderV2(
+	desc *descriptor.V2,
+	path Path,
+	scalars *ScalarRegistry,
+) (Decoder, error) {
+	typ, err := naturalTypeV2(desc, path, scalars)
+	if err != nil {
+		return nil, err
+	}
+
+	var child Decoder
+	switch typ {
+	case mapAnyType:
+		child, err = buildMapDecoderV2(desc, path, scalars)
+	case sliceAnyType:
+		if desc.Type == descriptor.Tuple {
+			child, err = buildTupleSliceDecoderV2(desc, path, scalars)
+		} else {
+			child, err = BuildDecoderV2(desc, typ, path, scalars)
+		}
+	default:
+		child, err = BuildDecoderV2(desc, typ, path, scalars)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &anyDecoder{child: child, typ: typ}, nil
+}
+
+// naturalTypeV2 returns the go type used
+// when decoding desc into an interface{}.
+// Scalars registered in scalars use their custom go types.
+func naturalTypeV2(
+	desc *descriptor.V2,
+	path Path,
+	scalars *ScalarRegistry,
+) (reflect.Type, error) {
+	switch desc.Type {
+	case descriptor.Object, descriptor.SQLRecord:
+		return mapAnyType, nil
+	case descriptor.Tuple:
+		if isNamedTupleV2(desc) {
+			return mapAnyType, nil
+		}
+		return sliceAnyType, nil
+	case descriptor.Set, descriptor.Array:
+		return sliceAnyType, nil
+	case descriptor.BaseScalar, descriptor.Scalar, descriptor.Enum:
+		if s, ok := scalars.lookup(desc); ok {
+			return s.typ, nil
+		}
+		return naturalScalarTypeV2(desc, path)
+	case descriptor.Range:
+		return naturalRangeTypeV2(&desc.Fields[0].Desc, path)
+	case descriptor.MultiRange:
+		typ, err := naturalRangeTypeV2(
+			&desc.Fields[0].Desc.Fields[0].Desc,
+			path,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(typ), nil
+	default:
+		return nil, fmt.Errorf(
+			"cannot decode %v into interface{}: unknown descriptor type 0x%x",
+			path, desc.Type)
+	}
+}
+
+func naturalScalarTypeV2(
+	desc *descriptor.V2,
+	path Path,
+) (reflect.Type, error) {
+	if desc.Type == descriptor.Scalar {
+		desc = GetScalarDescriptorV2(desc)
+	}
+
+	if desc.Type == descriptor.Enum {
+		return strType, nil
+	}
+
+	if desc.Name == VectorName {
+		return vectorType, nil
+	}
+
+	switch desc.ID {
+	case UUIDID:
+		return uuidType, nil
+	case StrID:
+		return strType, nil
+	case BytesID:
+		return bytesType, nil
+	case Int16ID:
+		return int16Type, nil
+	case Int32ID:
+		return int32Type, nil
+	case Int64ID:
+		return int64Type, nil
+	case Float32ID:
+		return float32Type, nil
+	case Float64ID:
+		return float64Type, nil
+	case BoolID:
+		return boolType, nil
+	case DateTimeID:
+		return dateTimeType, nil
+	case LocalDTID:
+		return localDateTimeType, nil
+	case LocalDateID:
+		return localDateType, nil
+	case LocalTimeID:
+		return localTimeType, nil
+	case DurationID:
+		return durationType, nil
+	case BigIntID:
+		return bigIntType, nil
+	case DecimalID:
+		return decimalType, nil
+	case RelativeDurationID:
+		return relativeDurationType, nil
+	case DateDurationID:
+		return dateDurationType, nil
+	case MemoryID:
+		return memoryType, nil
+	default:
+		return nil, fmt.Errorf(
+			"cannot decode %v into interface{}: "+
+				"unsupported scalar type id %v", path, desc.ID)
+	}
+}
+
+func naturalRangeTypeV2(
+	desc *descriptor.V2,
+	path Path,
+) (reflect.Type, error) {
+	if desc.Type == descriptor.Scalar {
+		desc = GetScalarDescriptorV2(desc)
+	}
+
+	switch desc.ID {
+	case Int32ID:
+		return rangeInt32Type, nil
+	case Int64ID:
+		return rangeInt64Type, nil
+	case Float32ID:
+		return rangeFloat32Type, nil
+	case Float64ID:
+		return rangeFloat64Type, nil
+	case DateTimeID:
+		return rangeDateTimeType, nil
+	case LocalDTID:
+		return rangeLocalDateTimeType, nil
+	case LocalDateID:
+		return rangeLocalDateType, nil
+	default:
+		return nil, fmt.Errorf(
+			"cannot decode %v into interface{}: "+
+				"unsupported range element type id %v", path, desc.ID)
+	}
+}
+
+// anyDecoder decodes into an interface{}
+// by decoding into a new value of type typ.
+type anyDecoder struct {
+	child Decoder
+	typ   reflect.Type
+}
+
+func (c *anyDecoder) DescriptorID() types.UUID {
+	return c.child.DescriptorID()
+}
+
+func (c *anyDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	val := reflect.New(c.typ)
+	err := c.child.Decode(r, unsafe.Pointer(val.Pointer()))
+	if err != nil {
+		return err
+	}
+
+	*(*interface{})(out) = val.Elem().Interface()
+	return nil
+}
+
+func (c *anyDecoder) DecodeMissing(out unsafe.Pointer) {
+	*(*interface{})(out) = nil
+}
+
+func buildMapDecoderV2(
+	desc *descriptor.V2,
+	path Path,
+	scalars *ScalarRegistry,
+) (Decoder, error) {
+	fields, err := buildAnyFieldsV2(desc, path, scalars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mapDecoder{desc.ID, fields}, nil
+}
+
+func buildTupleSliceDecoderV2(
+	desc *descriptor.V2,
+	path Path,
+	scalars *ScalarRegistry,
+) (Decoder, error) {
+	fields, err := buildAnyFieldsV2(desc, path, scalars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tupleSliceDecoder{desc.ID, fields}, nil
+}
+
+func buildAnyFieldsV2(
+	desc *descriptor.V2,
+	path Path,
+	scalars *ScalarRegistry,
+) ([]*DecoderField, error) {
+	fields := make([]*DecoderField, len(desc.Fields))
+	for i, field := range desc.Fields {
+		child, err := BuildDecoderV2(
+			&field.Desc,
+			anyType,
+			path.AddField(field.Name),
+			scalars,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = &DecoderField{name: field.Name, decoder: child}
+	}
+
+	return fields, nil
+}
+
+// decodeAnyFields calls fn with the decoded value of each element in an
+// object or tuple. Missing elements are decoded as nil.
+func decodeAnyFields(
+	r *buff.Reader,
+	fields []*DecoderField,
+	fn func(int, interface{}),
+) error {
+	elmCount := int(int32(r.PopUint32()))
+	if elmCount != len(fields) {
+		return fmt.Errorf(
+			"wrong number of elements: expected %v, got %v",
+			len(fields), elmCount)
+	}
+
+	for i, field := range fields {
+		r.Discard(4) // reserved
+
+		var val interface{}
+		elmLen := r.PopUint32()
+		if elmLen != 0xffffffff {
+			err := field.decoder.Decode(
+				r.PopSlice(elmLen),
+				unsafe.Pointer(&val),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		fn(i, val)
+	}
+
+	return nil
+}
+
+// mapDecoder decodes objects and named tuples
+// into map[string]interface{}.
+type mapDecoder struct {
+	id     types.UUID
+	fields []*DecoderField
+}
+
+func (c *mapDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *mapDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	m := make(map[string]interface{}, len(c.fields))
+	err := decodeAnyFields(r, c.fields, func(i int, val interface{}) {
+		m[c.fields[i].name] = val
+	})
+	if err != nil {
+		return err
+	}
+
+	*(*map[string]interface{})(out) = m
+	return nil
+}
+
+func (c *mapDecoder) DecodeMissing(out unsafe.Pointer) {
+	*(*map[string]interface{})(out) = nil
+}
+
+// tupleSliceDecoder decodes tuples into []interface{}.
+type tupleSliceDecoder struct {
+	id     types.UUID
+	fields []*DecoderField
+}
+
+func (c *tupleSliceDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *tupleSliceDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	s := make([]interface{}, len(c.fields))
+	err := decodeAnyFields(r, c.fields, func(i int, val interface{}) {
+		s[i] = val
+	})
+	if err != nil {
+		return err
+	}
+
+	*(*[]interface{})(out) = s
+	return nil
+}
+
+func (c *tupleSliceDecoder) DecodeMissing(out unsafe.Pointer) {
+	*(*[]interface{})(out) = nil
+}
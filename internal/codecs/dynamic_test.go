@@ -0,0 +1,163 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	int64DescV2 = descriptor.V2{
+		Type: descriptor.Scalar,
+		ID:   Int64ID,
+		Name: "std::int64",
+	}
+	strDescV2 = descriptor.V2{
+		Type: descriptor.Scalar,
+		ID:   StrID,
+		Name: "std::str",
+	}
+	objectDescV2 = descriptor.V2{
+		Type: descriptor.Object,
+		ID:   types.UUID{1},
+		Fields: []*descriptor.FieldV2{
+			{Name: "name", Desc: strDescV2, Required: true},
+			{Name: "age", Desc: int64DescV2},
+		},
+	}
+	tupleDescV2 = descriptor.V2{
+		Type: descriptor.Tuple,
+		ID:   types.UUID{2},
+		Fields: []*descriptor.FieldV2{
+			{Name: "0", Desc: int64DescV2},
+			{Name: "1", Desc: strDescV2},
+		},
+	}
+	namedTupleDescV2 = descriptor.V2{
+		Type: descriptor.Tuple,
+		ID:   types.UUID{3},
+		Fields: []*descriptor.FieldV2{
+			{Name: "a", Desc: int64DescV2},
+			{Name: "b", Desc: strDescV2},
+		},
+	}
+	arrayDescV2 = descriptor.V2{
+		Type:   descriptor.Array,
+		ID:     types.UUID{4},
+		Fields: []*descriptor.FieldV2{{Desc: int64DescV2}},
+	}
+)
+
+func decodeV2(
+	t *testing.T,
+	desc *descriptor.V2,
+	out interface{},
+	data []byte,
+) {
+	typ := reflect.TypeOf(out).Elem()
+	decoder, err := BuildDecoderV2(desc, typ, Path(typ.String()), nil)
+	require.NoError(t, err)
+
+	r := buff.SimpleReader(data)
+	err = decoder.Decode(r, unsafe.Pointer(reflect.ValueOf(out).Pointer()))
+	require.NoError(t, err)
+	assert.Empty(t, r.Buf)
+}
+
+func TestDecodeObjectIntoMap(t *testing.T) {
+	data := []byte{
+		0, 0, 0, 2, // element count
+		0, 0, 0, 0, // reserved
+		0, 0, 0, 5, // data length
+		'h', 'e', 'l', 'l', 'o',
+		0, 0, 0, 0, // reserved
+		0xff, 0xff, 0xff, 0xff, // missing
+	}
+
+	expected := map[string]interface{}{"name": "hello", "age": nil}
+
+	var m map[string]interface{}
+	decodeV2(t, &objectDescV2, &m, data)
+	assert.Equal(t, expected, m)
+
+	var a interface{}
+	decodeV2(t, &objectDescV2, &a, data)
+	assert.Equal(t, expected, a)
+}
+
+func TestDecodeTupleIntoSlice(t *testing.T) {
+	data := []byte{
+		0, 0, 0, 2, // element count
+		0, 0, 0, 0, // reserved
+		0, 0, 0, 8, // data length
+		0, 0, 0, 0, 0, 0, 0, 7,
+		0, 0, 0, 0, // reserved
+		0, 0, 0, 1, // data length
+		'x',
+	}
+
+	expected := []interface{}{int64(7), "x"}
+
+	var s []interface{}
+	decodeV2(t, &tupleDescV2, &s, data)
+	assert.Equal(t, expected, s)
+
+	var a interface{}
+	decodeV2(t, &tupleDescV2, &a, data)
+	assert.Equal(t, expected, a)
+
+	var m map[string]interface{}
+	decodeV2(t, &namedTupleDescV2, &m, data)
+	assert.Equal(t, map[string]interface{}{"a": int64(7), "b": "x"}, m)
+}
+
+func TestDecodeArrayIntoAny(t *testing.T) {
+	data := []byte{
+		0, 0, 0, 1, // number of dimensions
+		0, 0, 0, 0, // reserved
+		0, 0, 0, 0, // reserved
+		0, 0, 0, 2, // dimension upper
+		0, 0, 0, 1, // dimension lower
+		0, 0, 0, 8, // data length
+		0, 0, 0, 0, 0, 0, 0, 1,
+		0, 0, 0, 8, // data length
+		0, 0, 0, 0, 0, 0, 0, 2,
+	}
+
+	var a interface{}
+	decodeV2(t, &arrayDescV2, &a, data)
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, a)
+}
+
+func TestDecodeMismatchedDynamicType(t *testing.T) {
+	_, err := BuildDecoderV2(
+		&objectDescV2,
+		reflect.TypeOf([]interface{}{}),
+		Path("[]interface {}"),
+		nil,
+	)
+	assert.EqualError(t, err,
+		"expected []interface {} to be a Struct got slice")
+}
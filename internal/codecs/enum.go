@@ -0,0 +1,129 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/edgedb/edgedb-go/internal/marshal"
+)
+
+// enumEncoder encodes enum values. Values are checked against the enum's
+// members before they are sent to the server.
+type enumEncoder struct {
+	id types.UUID
+
+	// name is the enum's schema name.
+	// It is empty for protocol versions that don't send type names.
+	name string
+
+	// members are the enum's member names.
+	// Values are not checked if members is empty.
+	members []string
+}
+
+func (c *enumEncoder) DescriptorID() types.UUID { return c.id }
+
+// Encode encodes an enum value.
+func (c *enumEncoder) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	switch in := val.(type) {
+	case string:
+		return c.encodeData(w, in, path)
+	case types.OptionalStr:
+		str, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, str, path) },
+			func() error {
+				return missingValueError("edgedb.OptionalStr", path)
+			})
+	case optionalStrMarshaler:
+		return encodeOptional(w, in.Missing(), required,
+			func() error { return c.encodeMarshaler(w, in, path) },
+			func() error { return missingValueError(in, path) })
+	case marshal.StrMarshaler:
+		return c.encodeMarshaler(w, in, path)
+	}
+
+	// Allow user defined string types, for example type Color string.
+	if v := reflect.ValueOf(val); v.Kind() == reflect.String {
+		return c.encodeData(w, v.String(), path)
+	}
+
+	return fmt.Errorf("expected %v to be string, edgedb.OptionalStr "+
+		"or StrMarshaler got %T", path, val)
+}
+
+func (c *enumEncoder) encodeData(
+	w *buff.Writer,
+	data string,
+	path Path,
+) error {
+	if err := c.validate(data, path); err != nil {
+		return err
+	}
+
+	w.PushString(data)
+	return nil
+}
+
+func (c *enumEncoder) encodeMarshaler(
+	w *buff.Writer,
+	val marshal.StrMarshaler,
+	path Path,
+) error {
+	data, err := val.MarshalEdgeDBStr()
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(string(data), path); err != nil {
+		return err
+	}
+
+	w.PushUint32(uint32(len(data)))
+	w.PushBytes(data)
+	return nil
+}
+
+func (c *enumEncoder) validate(data string, path Path) error {
+	if len(c.members) == 0 {
+		return nil
+	}
+
+	for _, member := range c.members {
+		if data == member {
+			return nil
+		}
+	}
+
+	name := c.name
+	if name == "" {
+		name = "enum"
+	}
+
+	return fmt.Errorf("invalid value %q for %v at %v, expected one of: %v",
+		data, name, path, strings.Join(c.members, ", "))
+}
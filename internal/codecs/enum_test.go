@@ -0,0 +1,72 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"testing"
+
+	"github.com/edgedb/edgedb-go/internal"
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type color string
+
+var colorDescV2 = descriptor.V2{
+	Type:        descriptor.Enum,
+	ID:          types.UUID{5},
+	Name:        "default::Color",
+	EnumMembers: []string{"Red", "Green", "Blue"},
+}
+
+func TestEncodeEnum(t *testing.T) {
+	encoder, err := BuildEncoderV2(
+		&colorDescV2,
+		internal.ProtocolVersion{Major: 2, Minor: 0},
+		nil,
+	)
+	require.NoError(t, err)
+
+	expected := []byte{0, 0, 0, 3, 'R', 'e', 'd'}
+	for _, val := range []interface{}{
+		"Red",
+		types.NewOptionalStr("Red"),
+		color("Red"),
+	} {
+		w := buff.NewWriter(nil)
+		require.NoError(t, encoder.Encode(w, val, Path("args[0]"), true))
+		assert.Equal(t, expected, w.Unwrap())
+	}
+
+	w := buff.NewWriter(nil)
+	err = encoder.Encode(w, color("Purple"), Path("args[0]"), true)
+	assert.EqualError(t, err, `invalid value "Purple" for default::Color `+
+		`at args[0], expected one of: Red, Green, Blue`)
+}
+
+func TestDecodeEnumIntoStringType(t *testing.T) {
+	var result color
+	decodeV2(t, &colorDescV2, &result, []byte("Green"))
+	assert.Equal(t, color("Green"), result)
+
+	var optional types.OptionalStr
+	decodeV2(t, &colorDescV2, &optional, []byte("Blue"))
+	assert.Equal(t, types.NewOptionalStr("Blue"), optional)
+}
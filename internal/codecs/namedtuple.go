@@ -155,6 +155,7 @@ func buildNamedTupleDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
 	if typ.Kind() != reflect.Struct {
 		return nil, fmt.Errorf(
@@ -176,6 +177,7 @@ func buildNamedTupleDecoderV2(
 			&field.Desc,
 			sf.Type,
 			path.AddField(field.Name),
+			scalars,
 		)
 
 		if err != nil {
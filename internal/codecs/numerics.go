@@ -18,6 +18,7 @@ package codecs
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 	"unsafe"
@@ -212,22 +213,83 @@ func (c *optionalBigIntDecoder) DecodeMissing(out unsafe.Pointer) {
 
 func (c *optionalBigIntDecoder) DecodePresent(_ unsafe.Pointer) {}
 
-type decimalEncoder struct{}
+// DecimalCodec encodes/decodes edgedb.Decimal.
+type DecimalCodec struct{}
 
-func (c *decimalEncoder) DescriptorID() types.UUID { return DecimalID }
+// Type returns the type the codec encodes/decodes
+func (c *DecimalCodec) Type() reflect.Type { return decimalType }
+
+// DescriptorID returns the codecs descriptor id.
+func (c *DecimalCodec) DescriptorID() types.UUID { return DecimalID }
+
+// Decode decodes an edgedb.Decimal
+func (c *DecimalCodec) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	d, err := decodeDecimal(r)
+	if err != nil {
+		return err
+	}
+
+	*(*types.Decimal)(out) = d
+	return nil
+}
+
+func decodeDecimal(r *buff.Reader) (types.Decimal, error) {
+	n := int(r.PopUint16())
+	weight := int(int16(r.PopUint16()))
+	sign := r.PopUint16()
+	scale := int(r.PopUint16())
+
+	if sign != 0 && sign != 0x4000 {
+		return types.Decimal{}, fmt.Errorf(
+			"unexpected decimal sign: 0x%x", sign)
+	}
+
+	// The value is the base 10000 digits
+	// times 10000^(weight - n + 1).
+	unscaled := &big.Int{}
+	digit := &big.Int{}
+	for i := 0; i < n; i++ {
+		unscaled.Mul(unscaled, big10k)
+		digit.SetUint64(uint64(r.PopUint16()))
+		unscaled.Add(unscaled, digit)
+	}
+
+	exp := 4*(weight-n+1) + scale
+	if exp >= 0 {
+		unscaled.Mul(unscaled, types.Pow10(exp))
+	} else {
+		unscaled.Quo(unscaled, types.Pow10(-exp))
+	}
+
+	if sign == 0x4000 {
+		unscaled.Neg(unscaled)
+	}
+
+	return types.NewDecimal(unscaled, scale), nil
+}
 
 type optionalDecimalMarshaler interface {
 	marshal.DecimalMarshaler
 	marshal.OptionalMarshaler
 }
 
-func (c *decimalEncoder) Encode(
+// Encode encodes an edgedb.Decimal.
+func (c *DecimalCodec) Encode(
 	w *buff.Writer,
 	val interface{},
 	path Path,
 	required bool,
 ) error {
 	switch in := val.(type) {
+	case types.Decimal:
+		return c.encodeData(w, in)
+	case types.OptionalDecimal:
+		data, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, data) },
+			func() error {
+				return missingValueError("edgedb.OptionalDecimal", path)
+			})
 	case optionalDecimalMarshaler:
 		return encodeOptional(w, in.Missing(), required,
 			func() error { return c.encodeMarshaler(w, in, path) },
@@ -235,12 +297,69 @@ func (c *decimalEncoder) Encode(
 	case marshal.DecimalMarshaler:
 		return c.encodeMarshaler(w, in, path)
 	default:
-		return fmt.Errorf("expected %v to be DecimalMarshaler got %T",
-			path, val)
+		return fmt.Errorf("expected %v to be edgedb.Decimal, "+
+			"edgedb.OptionalDecimal or DecimalMarshaler got %T", path, val)
 	}
 }
 
-func (c *decimalEncoder) encodeMarshaler(
+func (c *DecimalCodec) encodeData(w *buff.Writer, val types.Decimal) error {
+	scale := val.Scale()
+	if scale > 0xffff {
+		return fmt.Errorf("decimal scale %v is too large", scale)
+	}
+
+	unscaled := val.Unscaled()
+	var sign uint16
+	if unscaled.Sign() == -1 {
+		sign = 0x4000
+		unscaled.Neg(unscaled)
+	}
+
+	// Pad the scale to a multiple of 4
+	// so that the digits line up with base 10000 digits.
+	padding := (4 - scale%4) % 4
+	unscaled.Mul(unscaled, types.Pow10(padding))
+
+	var digits []uint16
+	rem := &big.Int{}
+	for unscaled.Sign() != 0 {
+		unscaled.QuoRem(unscaled, big10k, rem)
+		digits = append(digits, uint16(rem.Uint64()))
+	}
+
+	// digits are least significant first
+	weight := len(digits) - 1 - (scale+padding)/4
+
+	// trailing zeros are implied
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+	}
+
+	if len(digits) == 0 {
+		weight = 0
+	}
+
+	if weight < math.MinInt16 || weight > math.MaxInt16 {
+		return fmt.Errorf("decimal weight %v is out of range", weight)
+	}
+
+	if len(digits) > 0xffff {
+		return fmt.Errorf("decimal has too many digits: %v", len(digits))
+	}
+
+	w.BeginBytes()
+	w.PushUint16(uint16(len(digits)))
+	w.PushUint16(uint16(int16(weight)))
+	w.PushUint16(sign)
+	w.PushUint16(uint16(scale))
+	for i := len(digits) - 1; i >= 0; i-- {
+		w.PushUint16(digits[i])
+	}
+	w.EndBytes()
+	return nil
+}
+
+func (c *DecimalCodec) encodeMarshaler(
 	w *buff.Writer,
 	val marshal.DecimalMarshaler,
 	path Path,
@@ -257,3 +376,28 @@ func (c *decimalEncoder) encodeMarshaler(
 	w.EndBytes()
 	return nil
 }
+
+type optionalDecimalDecoder struct{}
+
+func (c *optionalDecimalDecoder) DescriptorID() types.UUID {
+	return DecimalID
+}
+
+func (c *optionalDecimalDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	d, err := decodeDecimal(r)
+	if err != nil {
+		return err
+	}
+
+	*(*types.OptionalDecimal)(out) = types.NewOptionalDecimal(d)
+	return nil
+}
+
+func (c *optionalDecimalDecoder) DecodeMissing(out unsafe.Pointer) {
+	(*types.OptionalDecimal)(out).Unset()
+}
+
+func (c *optionalDecimalDecoder) DecodePresent(_ unsafe.Pointer) {}
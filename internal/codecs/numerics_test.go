@@ -0,0 +1,121 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"math/big"
+	"testing"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecimal(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []byte
+	}{
+		{"0", []byte{
+			0, 0, 0, 8, // data length
+			0, 0, // ndigits
+			0, 0, // weight
+			0, 0, // sign
+			0, 0, // dscale
+		}},
+		{"123.45", []byte{
+			0, 0, 0, 0x0c, // data length
+			0, 2, // ndigits
+			0, 0, // weight
+			0, 0, // sign
+			0, 2, // dscale
+			0, 123, 0x11, 0x94, // 123, 4500
+		}},
+		{"-0.00012000", []byte{
+			0, 0, 0, 0x0c, // data length
+			0, 2, // ndigits
+			0xff, 0xff, // weight -1
+			0x40, 0, // sign
+			0, 8, // dscale
+			0, 1, 0x07, 0xd0, // 1, 2000
+		}},
+		{"100000000", []byte{
+			0, 0, 0, 0x0a, // data length
+			0, 1, // ndigits
+			0, 2, // weight
+			0, 0, // sign
+			0, 0, // dscale
+			0, 1, // 1
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			d, err := types.ParseDecimal(c.input)
+			require.NoError(t, err)
+
+			w := buff.NewWriter(nil)
+			w.BeginMessage(0)
+			err = (&DecimalCodec{}).Encode(w, d, Path("decimal"), true)
+			require.NoError(t, err)
+			w.EndMessage()
+
+			// skip the message type and length
+			data := w.Unwrap()[5:]
+			assert.Equal(t, c.expected, data)
+
+			var result types.Decimal
+			r := buff.SimpleReader(data[4:])
+			err = (&DecimalCodec{}).Decode(r, unsafe.Pointer(&result))
+			require.NoError(t, err)
+			assert.Equal(t, c.input, result.String())
+			assert.Empty(t, r.Buf)
+		})
+	}
+}
+
+func TestEncodeDecimalOutOfRange(t *testing.T) {
+	// The weight of 10^131100 doesn't fit in an int16.
+	d := types.NewDecimal(big.NewInt(1), -131100)
+	w := buff.NewWriter(nil)
+	w.BeginMessage(0)
+	err := (&DecimalCodec{}).Encode(w, d, Path("decimal"), true)
+	assert.EqualError(t, err, "decimal weight 32775 is out of range")
+}
+
+func TestDecodeOptionalDecimal(t *testing.T) {
+	data := []byte{
+		0, 1, // ndigits
+		0, 0, // weight
+		0, 0, // sign
+		0, 1, // dscale
+		0, 7, // 7
+	}
+
+	var result types.OptionalDecimal
+	err := (&optionalDecimalDecoder{}).Decode(
+		buff.SimpleReader(data),
+		unsafe.Pointer(&result),
+	)
+	require.NoError(t, err)
+
+	d, ok := result.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "7.0", d.String())
+}
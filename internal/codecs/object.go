@@ -44,10 +44,12 @@ var optionalTypeNameLookup = map[reflect.Type]string{
 	reflect.TypeOf(&Float32Codec{}):      "edgedb.OptionalFloat32",
 	reflect.TypeOf(&Float64Codec{}):      "edgedb.OptionalFloat64",
 	reflect.TypeOf(&BigIntCodec{}):       "edgedb.OptionalBigInt",
+	reflect.TypeOf(&DecimalCodec{}):      "edgedb.OptionalDecimal",
 	reflect.TypeOf(&objectDecoder{}):     "edgedb.Optional",
 	reflect.TypeOf(&StrCodec{}):          "edgedb.OptionalStr",
 	reflect.TypeOf(&tupleDecoder{}):      "edgedb.Optional",
 	reflect.TypeOf(&UUIDCodec{}):         "edgedb.OptionalUUID",
+	reflect.TypeOf(&VectorCodec{}):       "edgedb.OptionalVector",
 }
 
 func buildObjectDecoder(
@@ -112,6 +114,7 @@ func buildObjectDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
 	if typ.Kind() != reflect.Struct {
 		return nil, fmt.Errorf(
@@ -133,6 +136,7 @@ func buildObjectDecoderV2(
 			&field.Desc,
 			sf.Type,
 			path.AddField(field.Name),
+			scalars,
 		)
 		if err != nil {
 			return nil, err
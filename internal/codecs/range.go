@@ -247,6 +247,7 @@ func buildFieldV2(
 			desc,
 			sf.Type,
 			path.AddField(name),
+			nil,
 		)
 		if err != nil {
 			return reflect.StructField{}, nil, err
@@ -446,7 +447,7 @@ func buildRangeEncoderV2(
 	desc *descriptor.V2,
 	version internal.ProtocolVersion,
 ) (Encoder, error) {
-	child, err := BuildEncoderV2(&desc.Fields[0].Desc, version)
+	child, err := BuildEncoderV2(&desc.Fields[0].Desc, version, nil)
 	if err != nil {
 		return nil, err
 	}
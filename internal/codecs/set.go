@@ -49,6 +49,7 @@ func buildSetDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
 	if typ.Kind() != reflect.Slice {
 		return nil, fmt.Errorf(
@@ -56,7 +57,12 @@ func buildSetDecoderV2(
 		)
 	}
 
-	child, err := BuildDecoderV2(&desc.Fields[0].Desc, typ.Elem(), path)
+	child, err := BuildDecoderV2(
+		&desc.Fields[0].Desc,
+		typ.Elem(),
+		path,
+		scalars,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +125,7 @@ func (c *setDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
 	slice := (*sliceHeader)(out)
 	setSliceLen(slice, c.typ, n)
 
-	_, isSetOfArrays := c.child.(*arrayDecoder)
+	isSetOfArrays := isArrayDecoder(c.child)
 
 	for i := 0; i < n; i++ {
 		if isSetOfArrays {
@@ -138,6 +144,15 @@ func (c *setDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
 	return nil
 }
 
+func isArrayDecoder(decoder Decoder) bool {
+	if d, ok := decoder.(*anyDecoder); ok {
+		decoder = d.child
+	}
+
+	_, ok := decoder.(*arrayDecoder)
+	return ok
+}
+
 func (c *setDecoder) DecodeMissing(out unsafe.Pointer) {
 	slice := (*sliceHeader)(out)
 	slice.Data = nilPointer
@@ -142,6 +142,7 @@ func buildTupleDecoderV2(
 	desc *descriptor.V2,
 	typ reflect.Type,
 	path Path,
+	scalars *ScalarRegistry,
 ) (Decoder, error) {
 	if typ.Kind() != reflect.Struct {
 		return nil, fmt.Errorf(
@@ -164,6 +165,7 @@ func buildTupleDecoderV2(
 			&field.Desc,
 			sf.Type,
 			path.AddField(field.Name),
+			scalars,
 		)
 
 		if err != nil {
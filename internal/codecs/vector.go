@@ -0,0 +1,158 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+)
+
+// VectorName is the name of the pgvector extension's vector type.
+// Extension types don't have fixed descriptor IDs
+// so the vector type is identified by its name.
+const VectorName = "ext::pgvector::vector"
+
+func buildVectorDecoderV2(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+) (Decoder, error) {
+	switch typ {
+	case vectorType:
+		return &VectorCodec{desc.ID}, nil
+	case optionalVectorType:
+		return &optionalVectorDecoder{desc.ID}, nil
+	default:
+		return nil, fmt.Errorf(
+			"expected %v to be []float32 or edgedb.OptionalVector got %v",
+			path, typ)
+	}
+}
+
+// VectorCodec encodes/decodes ext::pgvector::vector values.
+type VectorCodec struct {
+	ID types.UUID
+}
+
+// Type returns the type the codec encodes/decodes
+func (c *VectorCodec) Type() reflect.Type { return vectorType }
+
+// DescriptorID returns the codecs descriptor id.
+func (c *VectorCodec) DescriptorID() types.UUID { return c.ID }
+
+// Decode decodes a value
+func (c *VectorCodec) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	return decodeVector(r, (*[]float32)(out))
+}
+
+func decodeVector(r *buff.Reader, out *[]float32) error {
+	n := int(r.PopUint16())
+	r.Discard(2) // reserved
+
+	if len(r.Buf) != 4*n {
+		return fmt.Errorf(
+			"vector has %v dimensions but contains %v bytes of data",
+			n, len(r.Buf))
+	}
+
+	if cap(*out) >= n {
+		*out = (*out)[:n]
+	} else {
+		*out = make([]float32, n)
+	}
+
+	for i := 0; i < n; i++ {
+		(*out)[i] = math.Float32frombits(r.PopUint32())
+	}
+
+	return nil
+}
+
+// Encode encodes a value
+func (c *VectorCodec) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	switch in := val.(type) {
+	case []float32:
+		return c.encodeData(w, in, path)
+	case types.OptionalVector:
+		data, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, data, path) },
+			func() error {
+				return missingValueError("edgedb.OptionalVector", path)
+			})
+	default:
+		return fmt.Errorf("expected %v to be []float32 or "+
+			"edgedb.OptionalVector got %T", path, val)
+	}
+}
+
+func (c *VectorCodec) encodeData(
+	w *buff.Writer,
+	data []float32,
+	path Path,
+) error {
+	if len(data) > math.MaxUint16 {
+		return fmt.Errorf("cannot encode %v: vectors can have "+
+			"at most %v dimensions, got %v", path, math.MaxUint16, len(data))
+	}
+
+	w.BeginBytes()
+	w.PushUint16(uint16(len(data)))
+	w.PushUint16(0) // reserved
+	for _, v := range data {
+		w.PushUint32(math.Float32bits(v))
+	}
+	w.EndBytes()
+	return nil
+}
+
+type optionalVector struct {
+	val []float32
+	set bool
+}
+
+type optionalVectorDecoder struct {
+	id types.UUID
+}
+
+func (c *optionalVectorDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *optionalVectorDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	opvec := (*optionalVector)(out)
+	opvec.set = true
+	return decodeVector(r, &opvec.val)
+}
+
+func (c *optionalVectorDecoder) DecodeMissing(out unsafe.Pointer) {
+	(*types.OptionalVector)(out).Unset()
+}
+
+func (c *optionalVectorDecoder) DecodePresent(_ unsafe.Pointer) {}
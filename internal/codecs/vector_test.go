@@ -0,0 +1,100 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/edgedb/edgedb-go/internal/buff"
+	"github.com/edgedb/edgedb-go/internal/descriptor"
+	types "github.com/edgedb/edgedb-go/internal/edgedbtypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	vectorDescV2 = descriptor.V2{
+		Type: descriptor.Scalar,
+		ID:   types.UUID{7},
+		Name: VectorName,
+	}
+
+	// embeddingDescV2 is a scalar type that extends vector.
+	embeddingDescV2 = descriptor.V2{
+		Type:      descriptor.Scalar,
+		ID:        types.UUID{8},
+		Name:      "default::Embedding",
+		Ancestors: []*descriptor.FieldV2{{Desc: vectorDescV2}},
+	}
+
+	vectorData = []byte{
+		0, 3, // dimensions
+		0, 0, // reserved
+		0x3f, 0x80, 0, 0, // 1
+		0xc0, 0, 0, 0, // -2
+		0x3f, 0, 0, 0, // 0.5
+	}
+)
+
+func TestEncodeVector(t *testing.T) {
+	encoder, err := BuildScalarEncoderV2(&embeddingDescV2)
+	require.NoError(t, err)
+	assert.Equal(t, types.UUID{7}, encoder.DescriptorID())
+
+	for _, val := range []interface{}{
+		[]float32{1, -2, 0.5},
+		types.NewOptionalVector([]float32{1, -2, 0.5}),
+	} {
+		w := buff.NewWriter(nil)
+		w.BeginMessage(0)
+		require.NoError(t, encoder.Encode(w, val, Path("args[0]"), true))
+		w.EndMessage()
+
+		// skip the message type, message length and data length
+		assert.Equal(t, vectorData, w.Unwrap()[9:])
+	}
+
+	w := buff.NewWriter(nil)
+	w.BeginMessage(0)
+	err = encoder.Encode(w, []float64{1}, Path("args[0]"), true)
+	assert.EqualError(t, err, "expected args[0] to be []float32 or "+
+		"edgedb.OptionalVector got []float64")
+}
+
+func TestDecodeVector(t *testing.T) {
+	var result []float32
+	decodeV2(t, &embeddingDescV2, &result, vectorData)
+	assert.Equal(t, []float32{1, -2, 0.5}, result)
+
+	var optional types.OptionalVector
+	decodeV2(t, &vectorDescV2, &optional, vectorData)
+	assert.Equal(t, types.NewOptionalVector([]float32{1, -2, 0.5}), optional)
+
+	var any interface{}
+	decodeV2(t, &vectorDescV2, &any, vectorData)
+	assert.Equal(t, []float32{1, -2, 0.5}, any)
+
+	_, err := BuildDecoderV2(
+		&vectorDescV2,
+		reflect.TypeOf([]float64{}),
+		Path("out"),
+		nil,
+	)
+	assert.EqualError(t, err, "expected out to be []float32 or "+
+		"edgedb.OptionalVector got []float64")
+}
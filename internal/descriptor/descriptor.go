@@ -85,6 +85,9 @@ type Descriptor struct {
 	Type   Type
 	ID     edgedbtypes.UUID
 	Fields []*Field
+
+	// EnumMembers are the member names of an Enum descriptor.
+	EnumMembers []string
 }
 
 // Field represents the child of a descriptor
@@ -114,25 +117,25 @@ func Pop(
 			fields := []*Field{{
 				Desc: descriptors[r.PopUint16()],
 			}}
-			desc = Descriptor{Set, id, fields}
+			desc = Descriptor{Set, id, fields, nil}
 		case Object, InputShape:
 			fields, err := objectFields(r, descriptors, version)
 			if err != nil {
 				return Descriptor{}, err
 			}
-			desc = Descriptor{typ, id, fields}
+			desc = Descriptor{typ, id, fields, nil}
 		case BaseScalar:
-			desc = Descriptor{BaseScalar, id, nil}
+			desc = Descriptor{BaseScalar, id, nil, nil}
 		case Scalar:
 			desc = Descriptor{Scalar, id, []*Field{{
 				Desc: descriptors[r.PopUint16()],
-			}}}
+			}}, nil}
 		case Tuple:
 			fields := tupleFields(r, descriptors)
-			desc = Descriptor{Tuple, id, fields}
+			desc = Descriptor{Tuple, id, fields, nil}
 		case NamedTuple:
 			fields := namedTupleFields(r, descriptors)
-			desc = Descriptor{typ, id, fields}
+			desc = Descriptor{typ, id, fields, nil}
 		case Array:
 			fields := []*Field{{
 				Desc: descriptors[r.PopUint16()],
@@ -141,14 +144,14 @@ func Pop(
 			if err != nil {
 				return Descriptor{}, err
 			}
-			desc = Descriptor{typ, id, fields}
+			desc = Descriptor{typ, id, fields, nil}
 		case Enum:
-			discardEnumMemberNames(r)
-			desc = Descriptor{typ, id, nil}
+			members := enumMemberNames(r)
+			desc = Descriptor{typ, id, nil, members}
 		case Range:
 			desc = Descriptor{typ, id, []*Field{{
 				Desc: descriptors[r.PopUint16()],
-			}}}
+			}}, nil}
 		default:
 
 			if 0x80 <= typ {
@@ -246,9 +249,12 @@ func assertArrayDimensions(r *buff.Reader) error {
 	return nil
 }
 
-func discardEnumMemberNames(r *buff.Reader) {
+func enumMemberNames(r *buff.Reader) []string {
 	n := int(r.PopUint16())
+	members := make([]string, n)
 	for i := 0; i < n; i++ {
-		r.PopBytes() // enumeration member name
+		members[i] = r.PopString()
 	}
+
+	return members
 }
@@ -0,0 +1,325 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedbtypes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// These are the limits of the server's decimal type. They keep ParseDecimal
+// from allocating huge numbers for inputs like 1e2000000000.
+const (
+	decimalMaxWholeDigits = 131072
+	decimalMaxScale       = 16383
+)
+
+var (
+	bigTen  = big.NewInt(10)
+	bigTwo  = big.NewInt(2)
+	bigFive = big.NewInt(5)
+)
+
+// NewDecimal returns the Decimal unscaled * 10^-scale.
+// unscaled is copied and is not modified.
+func NewDecimal(unscaled *big.Int, scale int) Decimal {
+	if unscaled == nil || unscaled.Sign() == 0 {
+		if scale < 0 {
+			scale = 0
+		}
+		return Decimal{scale: scale}
+	}
+
+	u := new(big.Int).Set(unscaled)
+	if scale < 0 {
+		u.Mul(u, Pow10(-scale))
+		scale = 0
+	}
+
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// DecimalFromBigInt returns v as a Decimal.
+func DecimalFromBigInt(v *big.Int) Decimal {
+	return NewDecimal(v, 0)
+}
+
+// DecimalFromRat returns r as a Decimal. An error is returned
+// if r can not be represented exactly with a finite number of decimal digits,
+// for example 1/3.
+func DecimalFromRat(r *big.Rat) (Decimal, error) {
+	// r has a finite decimal representation
+	// if its denominator only has the prime factors 2 and 5.
+	denom := new(big.Int).Set(r.Denom())
+	twos := removeFactor(denom, bigTwo)
+	fives := removeFactor(denom, bigFive)
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		return Decimal{}, fmt.Errorf(
+			"%v can not be represented as a decimal", r.RatString())
+	}
+
+	scale := twos
+	if fives > scale {
+		scale = fives
+	}
+
+	u := new(big.Int).Mul(r.Num(), Pow10(scale))
+	u.Quo(u, r.Denom())
+	return NewDecimal(u, scale), nil
+}
+
+// removeFactor divides n by f until f no longer divides n
+// and returns the number of divisions.
+func removeFactor(n, f *big.Int) int {
+	count := 0
+	q, m := new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(n, f, m)
+		if m.Sign() != 0 {
+			return count
+		}
+		n.Set(q)
+		count++
+	}
+}
+
+// Pow10 returns 10**n.
+func Pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// ParseDecimal parses a decimal number like 123.45, -0.001 or 1.5e-3.
+// The number of digits after the decimal point, including trailing zeros,
+// is kept as the decimal's scale. Numbers with more than 131072 digits
+// before the decimal point or more than 16383 digits after it are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	str := s
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf(
+				"could not parse decimal from %q: invalid exponent", s)
+		}
+		if e > decimalMaxWholeDigits || e < -decimalMaxScale {
+			return Decimal{}, fmt.Errorf(
+				"could not parse decimal from %q: exponent out of range", s)
+		}
+		exp = e
+		str = str[:i]
+	}
+
+	neg := false
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	whole, frac, _ := strings.Cut(str, ".")
+	digits := whole + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("could not parse decimal from %q", s)
+	}
+
+	u, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("could not parse decimal from %q", s)
+	}
+
+	scale := len(frac) - exp
+	wholeDigits := len(strings.TrimLeft(whole, "0")) + exp
+	if scale > decimalMaxScale || wholeDigits > decimalMaxWholeDigits {
+		return Decimal{}, fmt.Errorf(
+			"could not parse decimal from %q: out of range", s)
+	}
+
+	if neg {
+		u.Neg(u)
+	}
+
+	return NewDecimal(u, scale), nil
+}
+
+// Decimal is an arbitrary precision decimal number. It is stored as an
+// unscaled integer and a scale, the number of digits after the decimal
+// point. The zero value is 0.
+type Decimal struct {
+	// unscaled is nil if the decimal is zero.
+	unscaled *big.Int
+	scale    int
+}
+
+// Unscaled returns a copy of d's unscaled value.
+// d is equal to Unscaled() * 10^-Scale().
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of digits after d's decimal point.
+func (d Decimal) Scale() int { return d.scale }
+
+// Sign returns -1 if d is negative, 0 if d is zero and 1 if d is positive.
+func (d Decimal) Sign() int {
+	if d.unscaled == nil {
+		return 0
+	}
+
+	return d.unscaled.Sign()
+}
+
+// BigInt returns the integer part of d. exact is false
+// if d has a non-zero fractional part that was truncated.
+func (d Decimal) BigInt() (v *big.Int, exact bool) {
+	if d.unscaled == nil {
+		return new(big.Int), true
+	}
+
+	q, m := new(big.Int).QuoRem(d.unscaled, Pow10(d.scale), new(big.Int))
+	return q, m.Sign() == 0
+}
+
+// Rat returns d as a *big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	if d.unscaled == nil {
+		return new(big.Rat)
+	}
+
+	return new(big.Rat).SetFrac(d.unscaled, Pow10(d.scale))
+}
+
+func (d Decimal) String() string {
+	var digits string
+	if d.unscaled != nil {
+		digits = new(big.Int).Abs(d.unscaled).String()
+	}
+
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+
+	var b strings.Builder
+	if d.Sign() < 0 {
+		b.WriteByte('-')
+	}
+
+	point := len(digits) - d.scale
+	b.WriteString(digits[:point])
+	if d.scale > 0 {
+		b.WriteByte('.')
+		b.WriteString(digits[point:])
+	}
+
+	return b.String()
+}
+
+// MarshalText returns d marshaled as text.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText unmarshals bytes into *d.
+func (d *Decimal) UnmarshalText(b []byte) error {
+	v, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
+
+// MarshalJSON returns d marshaled as a json number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON unmarshals a json number or string into *d.
+func (d *Decimal) UnmarshalJSON(bytes []byte) error {
+	str := string(bytes)
+	if len(str) > 0 && str[0] == '"' {
+		if err := json.Unmarshal(bytes, &str); err != nil {
+			return err
+		}
+	}
+
+	if str == "null" {
+		return errors.New("can not unmarshal null into edgedb.Decimal")
+	}
+
+	return d.UnmarshalText([]byte(str))
+}
+
+// NewOptionalDecimal is a convenience function for creating an
+// OptionalDecimal with its value set to v.
+func NewOptionalDecimal(v Decimal) OptionalDecimal {
+	o := OptionalDecimal{}
+	o.Set(v)
+	return o
+}
+
+// OptionalDecimal is an optional Decimal. Optional types must be used for
+// out parameters when a shape field is not required.
+type OptionalDecimal struct {
+	val   Decimal
+	isSet bool
+}
+
+// Get returns the value and a boolean indicating if the value is present.
+func (o OptionalDecimal) Get() (Decimal, bool) { return o.val, o.isSet }
+
+// Set sets the value.
+func (o *OptionalDecimal) Set(val Decimal) {
+	o.val = val
+	o.isSet = true
+}
+
+// Unset marks the value as missing.
+func (o *OptionalDecimal) Unset() {
+	o.val = Decimal{}
+	o.isSet = false
+}
+
+// MarshalJSON returns o marshaled as json.
+func (o OptionalDecimal) MarshalJSON() ([]byte, error) {
+	if o.isSet {
+		return json.Marshal(o.val)
+	}
+	return json.Marshal(nil)
+}
+
+// UnmarshalJSON unmarshals bytes into *o.
+func (o *OptionalDecimal) UnmarshalJSON(bytes []byte) error {
+	if bytes[0] == 0x6e { // null
+		o.Unset()
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, &o.val); err != nil {
+		return err
+	}
+	o.isSet = true
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edgedbtypes
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		scale    int
+	}{
+		{"0", "0", 0},
+		{"0.00", "0.00", 2},
+		{"123", "123", 0},
+		{"-123.450", "-123.450", 3},
+		{"+1.5", "1.5", 1},
+		{".5", "0.5", 1},
+		{"5.", "5", 0},
+		{"-0.001", "-0.001", 3},
+		{"1.5e3", "1500", 0},
+		{"1.5E-3", "0.0015", 4},
+		{"12345678901234567890.12345678901234567890",
+			"12345678901234567890.12345678901234567890", 20},
+		{"1e-16383", "0." + strings.Repeat("0", 16382) + "1", 16383},
+		{"0.01e131072", "1" + strings.Repeat("0", 131070), 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			d, err := ParseDecimal(c.input)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, d.String())
+			assert.Equal(t, c.scale, d.Scale())
+		})
+	}
+}
+
+func TestParseDecimalInvalid(t *testing.T) {
+	for _, input := range []string{
+		"", "-", ".", "1.2.3", "1e", "abc", "1_0",
+		"1e131073", "1e-16384", "1e2000000000", "1e-2000000000",
+		"1e-9223372036854775808", "10e131072", "0.1e-16383",
+	} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseDecimal(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecimalConversions(t *testing.T) {
+	d := NewDecimal(big.NewInt(-12345), 2)
+	assert.Equal(t, "-123.45", d.String())
+	assert.Equal(t, big.NewInt(-12345), d.Unscaled())
+	assert.Equal(t, -1, d.Sign())
+
+	i, exact := d.BigInt()
+	assert.Equal(t, big.NewInt(-123), i)
+	assert.False(t, exact)
+
+	i, exact = NewDecimal(big.NewInt(500), 2).BigInt()
+	assert.Equal(t, big.NewInt(5), i)
+	assert.True(t, exact)
+
+	assert.Equal(t, big.NewRat(-12345, 100), d.Rat())
+	assert.Equal(t, "42", DecimalFromBigInt(big.NewInt(42)).String())
+	assert.Equal(t, "4200", NewDecimal(big.NewInt(42), -2).String())
+	assert.Equal(t, "0", Decimal{}.String())
+
+	d, err := DecimalFromRat(big.NewRat(-1, 8))
+	require.NoError(t, err)
+	assert.Equal(t, "-0.125", d.String())
+
+	_, err = DecimalFromRat(big.NewRat(1, 3))
+	assert.EqualError(t, err, "1/3 can not be represented as a decimal")
+}
+
+func TestMarshalDecimal(t *testing.T) {
+	d, err := ParseDecimal("-1.50")
+	require.NoError(t, err)
+
+	b, err := json.Marshal(d)
+	require.NoError(t, err)
+	assert.Equal(t, "-1.50", string(b))
+
+	var number, str Decimal
+	require.NoError(t, json.Unmarshal([]byte(`-1.50`), &number))
+	require.NoError(t, json.Unmarshal([]byte(`"-1.50"`), &str))
+	assert.Equal(t, d, number)
+	assert.Equal(t, d, str)
+}
+
+func TestMarshalOptionalDecimal(t *testing.T) {
+	b, err := json.Marshal(OptionalDecimal{})
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(b))
+
+	d := NewDecimal(big.NewInt(15), 1)
+	b, err = json.Marshal(NewOptionalDecimal(d))
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", string(b))
+
+	var o OptionalDecimal
+	require.NoError(t, json.Unmarshal([]byte("1.5"), &o))
+	assert.Equal(t, NewOptionalDecimal(d), o)
+
+	require.NoError(t, json.Unmarshal([]byte("null"), &o))
+	assert.Equal(t, OptionalDecimal{}, o)
+}
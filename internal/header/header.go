@@ -25,6 +25,15 @@ type Header0pX map[uint16][]byte
 type Header1pX map[string]string
 
 const (
+	// ImplicitLimit tells the server to limit the number of results.
+	ImplicitLimit uint16 = 0xFF01
+
+	// ImplicitTypeNames tells the server to inject __tname__ into objects.
+	ImplicitTypeNames uint16 = 0xFF02
+
+	// ImplicitTypeIDs tells the server to inject __tid__ into objects.
+	ImplicitTypeIDs uint16 = 0xFF03
+
 	// AllowCapabilities tells the server what capabilities it should allow.
 	AllowCapabilities uint16 = 0xFF04
 	allCapabilities   uint64 = 0xffffffffffffffff
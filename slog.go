@@ -0,0 +1,29 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package edgedb
+
+import edgedb "github.com/edgedb/edgedb-go/internal/client"
+
+// SlogServerMessages returns an edgedb.ServerLogHandler that logs server
+// messages to logger. Attributes are added to the log record
+// along with the message code.
+//
+// SlogServerMessages requires Go 1.21 or later.
+var SlogServerMessages = edgedb.SlogServerMessages
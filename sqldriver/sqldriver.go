@@ -0,0 +1,78 @@
+// This source file is part of the EdgeDB open source project.
+//
+// Copyright EdgeDB Inc. and the EdgeDB authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqldriver registers an EdgeDB driver for database/sql.
+//
+// The driver is registered as "edgedb". DSNs have the same format as the
+// DSNs accepted by edgedb.CreateClientDSN. Queries are written in EdgeQL
+// unless the DSN has the query parameter query_language=sql.
+//
+//	import (
+//		"database/sql"
+//
+//		_ "github.com/edgedb/edgedb-go/sqldriver"
+//	)
+//
+//	db, err := sql.Open("edgedb", "edgedb://edgedb@localhost/main")
+//
+// Use NewConnector or NewSQLConnector to share an existing edgedb.Client
+// with database/sql.
+//
+//	db := sql.OpenDB(sqldriver.NewConnector(client))
+//
+// Positional arguments are passed as $0, $1, ... to EdgeQL queries and as
+// $1, $2, ... to SQL queries. EdgeQL queries also accept named arguments
+// created with sql.Named. A nil argument is sent as an empty optional
+// argument. Arguments are not converted except for int, which is passed
+// as int64, so use int16 and int32 values for int16 and int32 parameters.
+// Transactions started with sql.DB.BeginTx run on one connection;
+// only sql.LevelDefault and sql.LevelSerializable isolation levels are
+// supported.
+//
+// Query results are returned as one column per field for objects and
+// tuples, otherwise as a single column named result. Values that have no
+// database/sql/driver.Value equivalent are returned as strings if they
+// implement fmt.Stringer and as JSON otherwise.
+package sqldriver
+
+import (
+	"database/sql"
+
+	edgedb "github.com/edgedb/edgedb-go/internal/client"
+)
+
+func init() {
+	sql.Register("edgedb", Driver{})
+}
+
+// Driver is a database/sql/driver.Driver for EdgeDB.
+type Driver = edgedb.SQLDriver
+
+// Connector is a database/sql/driver.Connector backed by an edgedb.Client.
+type Connector = edgedb.SQLConnector
+
+// NewConnector returns a Connector that runs EdgeQL queries with client.
+// The client is not closed when the connector is closed.
+func NewConnector(client *edgedb.Client) *Connector {
+	return edgedb.NewEdgeQLConnector(client)
+}
+
+// NewSQLConnector returns a Connector that runs SQL queries with client.
+// SQL queries require EdgeDB 6.0 or newer.
+// The client is not closed when the connector is closed.
+func NewSQLConnector(client *edgedb.Client) *Connector {
+	return edgedb.NewSQLConnector(client)
+}